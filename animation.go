@@ -0,0 +1,171 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"log"
+	"sync"
+
+	"github.com/goplus/spx/internal/anim"
+)
+
+// -------------------------------------------------------------------------------------
+
+// Animation is a handle of an animation played by SpriteImpl.PlayAnimation.
+type Animation struct {
+	sprite   *SpriteImpl
+	name     SpriteAnimationName
+	channel  string
+	an       *anim.Anim
+	h        *tickHandler
+	wg       sync.WaitGroup
+	paused   bool
+	stopped  bool // stopped by Stop
+	replaced bool // replaced by another animation on the same channel
+
+	playDefault bool // play the default animation after it ends
+}
+
+// AnimationOpts are the options of SpriteImpl.PlayAnimation.
+type AnimationOpts struct {
+	// Animations on different channels play at the same time. The default
+	// channel "" is the one of Animate, Glide, Step, etc.
+	Channel string
+	Loop    bool    // play the animation in loop
+	Speed   float64 // 1 by default
+}
+
+func (p *Animation) Name() SpriteAnimationName {
+	return p.name
+}
+
+func (p *Animation) Pause() {
+	p.paused = true
+}
+
+func (p *Animation) Resume() {
+	p.paused = false
+}
+
+// Stop stops the animation, without firing OnAnimationEnd.
+func (p *Animation) Stop() {
+	p.stopped = true
+	p.an.Stop()
+}
+
+// SetSpeed sets the playing speed, 1 is the normal speed.
+func (p *Animation) SetSpeed(speed float64) {
+	if speed <= 0 {
+		log.Println("Animation.SetSpeed: invalid speed -", speed)
+		return
+	}
+	p.an.SetSpeedRatio(speed)
+}
+
+func (p *Animation) Speed() float64 {
+	return p.an.SpeedRatio()
+}
+
+// Progress returns the progress of the animation, or of the current loop of a
+// looping animation, from 0 to 1.
+func (p *Animation) Progress() float64 {
+	return p.an.Progress()
+}
+
+// Playing checks if the animation is playing, or paused.
+func (p *Animation) Playing() bool {
+	return p.an.Status() != anim.AnimstatusStop
+}
+
+// Wait waits until the animation ends, is stopped or is replaced.
+func (p *Animation) Wait() {
+	waitToDo(p.wg.Wait)
+}
+
+// -------------------------------------------------------------------------------------
+
+// PlayAnimation__0 plays an animation without waiting for it to end.
+func (p *SpriteImpl) PlayAnimation__0(name SpriteAnimationName) *Animation {
+	return p.PlayAnimation__1(name, nil)
+}
+
+// PlayAnimation__1 plays an animation without waiting for it to end. It
+// replaces the animation playing on the same channel.
+func (p *SpriteImpl) PlayAnimation__1(name SpriteAnimationName, opts *AnimationOpts) *Animation {
+	if debugInstr {
+		log.Println("==> PlayAnimation", name, opts)
+	}
+	ani, ok := p.animations[name]
+	if !ok {
+		log.Println("Animation not found:", name)
+		return nil
+	}
+	if opts == nil {
+		opts = &AnimationOpts{}
+	}
+	anicopy := *ani
+	anicopy.IsLoop = anicopy.IsLoop || opts.Loop
+	a := p.startAnimation(name, &anicopy, opts.Channel)
+	if opts.Speed > 0 {
+		a.SetSpeed(opts.Speed)
+	}
+	return a
+}
+
+// StopAnimation stops the animation playing on a channel, if any.
+func (p *SpriteImpl) StopAnimation(channel string) {
+	if a := p.playing[channel]; a != nil {
+		a.Stop()
+	}
+}
+
+// OnAnimationEnd__0 is called when an animation of the sprite plays to its
+// end. It isn't called if the animation is stopped or replaced.
+func (p *SpriteImpl) OnAnimationEnd__0(onEnd func(name SpriteAnimationName)) {
+	p.allWhenAnimationEnd = &eventSink{
+		prev:  p.allWhenAnimationEnd,
+		pthis: p,
+		sink:  onEnd,
+		cond: func(data interface{}) bool {
+			return data == p
+		},
+	}
+}
+
+func (p *SpriteImpl) OnAnimationEnd__1(name SpriteAnimationName, onEnd func()) {
+	p.OnAnimationEnd__0(func(ended SpriteAnimationName) {
+		if ended == name {
+			onEnd()
+		}
+	})
+}
+
+// OnAnimationFrame is called when animation name of the sprite reaches a
+// frame, counting from 0, like a footstep or a hit. Frames of frame animations
+// are their costumes, and frames of other animations are counted at their fps.
+func (p *SpriteImpl) OnAnimationFrame(name SpriteAnimationName, frame int, onFrame func()) {
+	p.allWhenAnimationFrame = &eventSink{
+		prev:  p.allWhenAnimationFrame,
+		pthis: p,
+		sink:  onFrame,
+		cond: func(data interface{}) bool {
+			return data == animationFrame{p, name, frame}
+		},
+	}
+}
+
+// -------------------------------------------------------------------------------------
@@ -0,0 +1,261 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"log"
+
+	"github.com/goplus/spx/internal/coroutine"
+)
+
+// -------------------------------------------------------------------------------------
+
+const (
+	animatorChannel  = "@animator" // channel of animations played by animators
+	animatorAnyState = "any"
+)
+
+// Animator is a state machine playing the animations of a sprite. Its states
+// and transitions are declared in the "animator" of the sprite in index.json,
+// and its transitions are driven by parameters set by scripts.
+type Animator struct {
+	sprite   *SpriteImpl
+	conf     *animatorConfig
+	states   map[string]*animatorStateConfig
+	params   map[string]float64 // bools are 0 or 1
+	triggers map[string]bool    // names of trigger parameters
+	state    string             // current state, "" before the first update
+	current  *Animation         // animation of the current state
+}
+
+func newAnimator(sprite *SpriteImpl, conf *animatorConfig) *Animator {
+	p := &Animator{
+		sprite:   sprite,
+		conf:     conf,
+		states:   make(map[string]*animatorStateConfig),
+		params:   make(map[string]float64),
+		triggers: make(map[string]bool),
+	}
+	if conf == nil {
+		return p
+	}
+	for _, state := range conf.States {
+		p.states[state.Name] = state
+	}
+	for name, v := range conf.Parameters {
+		if v == "trigger" {
+			p.triggers[name] = true
+		} else {
+			p.params[name] = toParamValue(v)
+		}
+	}
+	return p
+}
+
+// initFrom initializes the animator of a clone, which starts from the default
+// state with the current parameters of src.
+func (p *Animator) initFrom(sprite *SpriteImpl, src *Animator) {
+	*p = Animator{sprite: sprite, conf: src.conf, states: src.states, triggers: src.triggers}
+	p.params = make(map[string]float64, len(src.params))
+	for k, v := range src.params {
+		p.params[k] = v
+	}
+}
+
+func toParamValue(v interface{}) float64 {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case float64:
+		return v
+	case nil:
+		return 1
+	}
+	log.Println("Animator: invalid parameter value -", v)
+	return 0
+}
+
+// Animator returns the animator of the sprite.
+func (p *SpriteImpl) Animator() *Animator {
+	if p.animator == nil {
+		p.animator = newAnimator(p, nil)
+	}
+	return p.animator
+}
+
+func (p *Animator) SetBool(name string, v bool) {
+	p.params[name] = toParamValue(v)
+}
+
+func (p *Animator) Bool(name string) bool {
+	return p.params[name] != 0
+}
+
+func (p *Animator) SetFloat(name string, v float64) {
+	p.params[name] = v
+}
+
+func (p *Animator) Float(name string) float64 {
+	return p.params[name]
+}
+
+// SetTrigger sets a trigger parameter, which is reset when a transition
+// conditioned on it happens.
+func (p *Animator) SetTrigger(name string) {
+	p.triggers[name] = true
+	p.params[name] = 1
+}
+
+func (p *Animator) ResetTrigger(name string) {
+	p.params[name] = 0
+}
+
+// State returns the name of the current state.
+func (p *Animator) State() string {
+	return p.state
+}
+
+// Play goes to a state at once, without checking transitions.
+func (p *Animator) Play(state string) {
+	if _, ok := p.states[state]; !ok {
+		log.Println("Animator.Play: state not found -", state)
+		return
+	}
+	p.enter(state)
+}
+
+func (p *Animator) enter(state string) {
+	if debugInstr {
+		log.Println("Animator", p.sprite.name, p.state, "=>", state)
+	}
+	p.state = state
+	p.current = nil
+	conf := p.states[state]
+	name := conf.Animation
+	if name == "" {
+		name = conf.Name
+	}
+	ani, ok := p.sprite.animations[name]
+	if !ok {
+		log.Println("Animator: animation not found -", name)
+		return
+	}
+	anicopy := *ani
+	if conf.Loop != nil {
+		anicopy.IsLoop = *conf.Loop
+	}
+	p.current = p.sprite.startAnimation(name, &anicopy, animatorChannel)
+	if conf.Speed > 0 {
+		p.current.SetSpeed(conf.Speed)
+	}
+}
+
+// active checks if the animator has states to play.
+func (p *Animator) active() bool {
+	return p.conf != nil && len(p.conf.States) > 0
+}
+
+// progress returns the progress of the animation of the current state, 1 if
+// it ended.
+func (p *Animator) progress() float64 {
+	if p.current == nil || !p.current.Playing() {
+		return 1
+	}
+	return p.current.Progress()
+}
+
+// update starts the default state, or makes the first transition whose
+// conditions are met. Transitions from any state are checked first.
+func (p *Animator) update() {
+	if !p.active() {
+		return
+	}
+	if p.state == "" {
+		state := p.conf.Default
+		if state == "" {
+			state = p.conf.States[0].Name
+		}
+		p.enter(state)
+		return
+	}
+	for _, from := range [...]string{animatorAnyState, p.state} {
+		for _, t := range p.conf.Transitions {
+			if t.From != from || from == animatorAnyState && t.To == p.state {
+				continue
+			}
+			if p.canTransit(t) {
+				for _, c := range t.Conditions {
+					if p.triggers[c.Param] {
+						p.params[c.Param] = 0
+					}
+				}
+				p.enter(t.To)
+				return
+			}
+		}
+	}
+}
+
+func (p *Animator) canTransit(t *animatorTransitionConfig) bool {
+	if t.ExitTime != nil && p.progress() < *t.ExitTime {
+		return false
+	}
+	for _, c := range t.Conditions {
+		v, want := p.params[c.Param], toParamValue(c.Value)
+		var ok bool
+		switch c.Op {
+		case "", "==":
+			ok = v == want
+		case "!=":
+			ok = v != want
+		case ">":
+			ok = v > want
+		case ">=":
+			ok = v >= want
+		case "<":
+			ok = v < want
+		case "<=":
+			ok = v <= want
+		default:
+			log.Println("Animator: invalid condition op -", c.Op)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// updateAnimators advances the animators of all sprites, once a tick. Like
+// the tick handlers, it runs in a coroutine: entering a state starts an
+// animation and fires its actions, which must not run concurrently with
+// scripts.
+func (p *Game) updateAnimators() {
+	gco.CreateAndStart(true, nil, func(me coroutine.Thread) int {
+		for _, item := range p.items {
+			if sp, ok := item.(*SpriteImpl); ok && sp.animator != nil && !sp.isDying {
+				sp.animator.update()
+			}
+		}
+		return 0
+	})
+}
+
+// -------------------------------------------------------------------------------------
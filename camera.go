@@ -18,21 +18,60 @@ package spx
 
 import (
 	"log"
+	"math"
+	"math/rand"
+	"sync"
 
+	"github.com/goplus/spx/internal/anim"
 	"github.com/goplus/spx/internal/camera"
 	"github.com/goplus/spx/internal/math32"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+const (
+	cameraMinZoom   = 0.01
+	maxRenderLayers = 32
+)
+
 type Camera struct {
 	freecamera camera.FreeCamera
 	g          *Game
 	on_        interface{}
+	layerMask  uint32 // render layers shown by the camera
+
+	// follow parameters
+	deadZoneW, deadZoneH float64
+	lerp                 float64
+	lookAhead            float64
+
+	glideAnim *anim.Anim
+	shake     *tickHandler
 }
 
 func (c *Camera) init(g *Game, winW, winH float64, worldW, worldH float64) {
 	c.freecamera = *camera.NewFreeCamera(winW, winH, worldW, worldH)
 	c.g = g
+	c.layerMask = ^uint32(0)
+	c.deadZoneW, c.deadZoneH = 0, 0
+	c.lerp = 1
+	c.lookAhead = 0
+	c.glideAnim, c.shake = nil, nil
+}
+
+func (c *Camera) initWith(conf *cameraConfig) {
+	if conf.DeadZone != nil {
+		c.SetDeadZone(conf.DeadZone.Width, conf.DeadZone.Height)
+	}
+	if conf.Lerp != 0 {
+		c.SetFollowLerp(conf.Lerp)
+	}
+	c.SetLookAhead(conf.LookAhead)
+	if conf.Mask != nil {
+		c.layerMask = *conf.Mask
+	}
+	if conf.On != "" {
+		c.On__2(conf.On)
+	}
 }
 
 func (c *Camera) isWorldRange(pos *math32.Vector2) bool {
@@ -49,10 +88,152 @@ func (c *Camera) ChangeXYpos(x float64, y float64) {
 	c.freecamera.Move(x, y)
 }
 
+// SetZoom sets the zoom factor of the camera: 1 is the original size, values
+// greater than 1 zoom in and values between 0 and 1 zoom out.
+func (c *Camera) SetZoom(zoom float64) {
+	if zoom < cameraMinZoom {
+		zoom = cameraMinZoom
+	}
+	c.freecamera.SetZoom(zoom)
+}
+
+func (c *Camera) ChangeZoom(delta float64) {
+	c.SetZoom(c.freecamera.GetZoom() + delta)
+}
+
+func (c *Camera) Zoom() float64 {
+	return c.freecamera.GetZoom()
+}
+
+// SetRotation sets the rotation of the camera in degrees. Positive values turn
+// the camera clockwise, so the world appears to turn counterclockwise.
+func (c *Camera) SetRotation(degree float64) {
+	c.freecamera.SetRotation(toRadian(normalizeDirection(degree)))
+}
+
+func (c *Camera) ChangeRotation(delta float64) {
+	c.SetRotation(c.Rotation() + delta)
+}
+
+func (c *Camera) Rotation() float64 {
+	return c.freecamera.GetRotation() * 180 / math.Pi
+}
+
+// SetLayerMask sets the render layers shown by the camera: bit i of mask
+// stands for layer i. See SpriteImpl.SetRenderLayer.
+func (c *Camera) SetLayerMask(mask int) {
+	c.layerMask = uint32(mask)
+}
+
+func (c *Camera) LayerMask() int {
+	return int(c.layerMask)
+}
+
+// shows checks if item is in a render layer shown by the camera. Shapes other
+// than sprites are in layer 0.
+func (c *Camera) shows(item Shape) bool {
+	if sp, ok := item.(*SpriteImpl); ok {
+		return c.showsLayer(sp.renderLayer)
+	}
+	return c.showsLayer(0)
+}
+
+func (c *Camera) showsLayer(layer int) bool {
+	return c.layerMask&(1<<layer) != 0
+}
+
+// GlideTo moves the camera to (x, y) in secs seconds, using the specified
+// easing. It stops following any object and blocks until the move is done.
+func (c *Camera) GlideTo(x, y float64, secs float64, easing Easing) {
+	if debugInstr {
+		log.Println("Camera.GlideTo", x, y, secs, easing)
+	}
+	c.on_ = nil
+	if c.glideAnim != nil {
+		c.glideAnim.Stop()
+	}
+
+	const fps = 24.0
+	framenum := int(math.Max(secs*fps, 2))
+	pos := c.freecamera.GetPos()
+	an := anim.NewAnim("cameraGlide", fps, framenum, false)
+	an.SetEasingFunction(easing.function())
+	an.AddChannel(AnimChannelGlide, anim.AnimValTypeVector2, []*anim.AnimationKeyFrame{
+		{Frame: 0, Value: math32.NewVector2(pos.X, pos.Y)},
+		{Frame: framenum - 1, Value: math32.NewVector2(x, y)},
+	})
+	c.glideAnim = an
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	an.SetOnPlayingListener(func(currframe int, isReplay bool, progress float64) {
+		if val, ok := an.SampleChannel(AnimChannelGlide).(*math32.Vector2); ok {
+			c.freecamera.MoveTo(val.X, val.Y)
+		}
+	})
+	an.SetOnStopingListener(func() {
+		if c.glideAnim == an {
+			c.glideAnim = nil
+		}
+		wg.Done()
+	})
+
+	var h *tickHandler
+	h = c.g.startTick(-1, func(tick int64) {
+		if !an.Update(1000.0 / c.g.currentTPS() * float64(tick)) {
+			h.Stop()
+		}
+	})
+	waitToDo(wg.Wait)
+}
+
+// Shake shakes the camera for secs seconds. The camera is displaced randomly
+// by up to intensity, which decreases to 0 over time. It blocks until the
+// shake is done.
+func (c *Camera) Shake(intensity float64, secs float64) {
+	if debugInstr {
+		log.Println("Camera.Shake", intensity, secs)
+	}
+	total := int64(math.Max(secs*c.g.currentTPS(), 1))
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var h *tickHandler
+	h = c.g.startTick(total, func(tick int64) {
+		if c.shake != h { // replaced by a newer shake
+			h.Stop()
+			wg.Done()
+			return
+		}
+		if tick >= total {
+			c.shake = nil
+			c.freecamera.SetOffset(0, 0)
+			wg.Done()
+			return
+		}
+		amount := intensity * (1 - float64(tick)/float64(total))
+		c.freecamera.SetOffset((rand.Float64()*2-1)*amount, (rand.Float64()*2-1)*amount)
+	})
+	c.shake = h
+	waitToDo(wg.Wait)
+}
+
 func (c *Camera) screenToWorld(point *math32.Vector2) *math32.Vector2 {
 	return c.freecamera.ScreenToWorld(point)
 }
 
+func (c *Camera) viewSize() *math32.Vector2 {
+	return c.freecamera.ViewSize()
+}
+
+// viewRect returns the area of the world image seen by the camera, in world
+// image pixels.
+func (c *Camera) viewRect() (minX, minY, maxX, maxY float64) {
+	worldW, worldH := c.g.worldSize_()
+	pos, size := c.freecamera.GetPos(), c.viewSize()
+	cx, cy := float64(worldW)/2+pos.X, float64(worldH)/2-pos.Y
+	return cx - size.X/2, cy - size.Y/2, cx + size.X/2, cy + size.Y/2
+}
+
 /* unused:
 func (c *Camera) worldToScreen(point *math32.Vector2) *math32.Vector2 {
 	return c.freecamera.WorldToScreen(point)
@@ -99,21 +280,71 @@ func (c *Camera) On__3(obj specialObj) {
 	c.on(obj)
 }
 
-func (c *Camera) updateOnObj() {
+// SetDeadZone sets the size of a rectangle centered on the camera, in which
+// the followed object can move without moving the camera.
+func (c *Camera) SetDeadZone(width, height float64) {
+	c.deadZoneW, c.deadZoneH = math.Max(width, 0), math.Max(height, 0)
+}
+
+// SetFollowLerp sets the fraction (0, 1] of the remaining distance the camera
+// moves per tick when following an object. 1 snaps to the object.
+func (c *Camera) SetFollowLerp(lerp float64) {
+	c.lerp = math32.Clamp(lerp, 0.001, 1)
+}
+
+// SetLookAhead sets how far the camera looks ahead of the followed sprite, in
+// the direction of its heading.
+func (c *Camera) SetLookAhead(dist float64) {
+	c.lookAhead = dist
+}
+
+func (c *Camera) followTarget() (x, y float64, ok bool) {
 	switch v := c.on_.(type) {
 	case *SpriteImpl:
-		cx, cy := v.getXY()
-		c.freecamera.MoveTo(cx, cy)
-	case nil:
+		x, y = v.getXY()
+		if c.lookAhead != 0 {
+			sin, cos := math.Sincos(toRadian(v.direction))
+			x, y = x+c.lookAhead*sin, y+c.lookAhead*cos
+		}
+		return x, y, true
 	case specialObj:
-		cx := c.g.MouseX()
-		cy := c.g.MouseY()
-		c.freecamera.MoveTo(cx, cy)
+		return c.g.MouseX(), c.g.MouseY(), true
 	}
+	return
 }
 
-func (c *Camera) render(world, screen *ebiten.Image) error {
+// applyDeadZone returns the new camera coordinate on one axis, so that target
+// stays inside the dead zone [pos-zone/2, pos+zone/2].
+func applyDeadZone(pos, target, zone float64) float64 {
+	half := zone / 2
+	if target > pos+half {
+		return target - half
+	}
+	if target < pos-half {
+		return target + half
+	}
+	return pos
+}
+
+func (c *Camera) updateOnObj() {
+	tx, ty, ok := c.followTarget()
+	if !ok {
+		return
+	}
+	pos := c.freecamera.GetPos()
+	x := applyDeadZone(pos.X, tx, c.deadZoneW)
+	y := applyDeadZone(pos.Y, ty, c.deadZoneH)
+	if c.lerp < 1 {
+		x, y = lerp(pos.X, x, c.lerp), lerp(pos.Y, y, c.lerp)
+	}
+	c.freecamera.MoveTo(x, y) // clamped into the world
+}
+
+func (c *Camera) update() {
 	c.updateOnObj()
+}
+
+func (c *Camera) render(world, screen *ebiten.Image) error {
 	c.freecamera.Render(world, screen)
 	return nil
 }
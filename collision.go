@@ -0,0 +1,165 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"github.com/goplus/spx/internal/math32"
+	"github.com/goplus/spx/internal/spatial"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// -------------------------------------------------------------------------------------
+
+const (
+	colliderPixel   = "pixel"
+	colliderCircle  = "circle"
+	colliderBox     = "box"
+	colliderPolygon = "polygon"
+)
+
+// newCollisionShape creates the collision shape of a costume in stage
+// coordinates, or returns nil if the costume collides by its pixels. geo maps
+// costume image pixels to stage coordinates, (cx, cy) is the costume center in
+// image pixels and scale is the scale from image pixels to stage.
+func newCollisionShape(conf *colliderConfig, imgW, imgH, cx, cy float64, geo *ebiten.GeoM, scale float64) spatial.Shape {
+	if conf == nil {
+		return nil
+	}
+	s := conf.Scale
+	if s == 0 {
+		s = 1
+	}
+	// collider coordinates are relative to the costume center, y pointing up
+	toStage := func(x, y float64) spatial.Point {
+		x, y = geo.Apply(cx+conf.Offset.X+x*s, cy-conf.Offset.Y-y*s)
+		return spatial.Point{X: x, Y: y}
+	}
+	switch conf.Type {
+	case colliderCircle:
+		center := toStage(0, 0)
+		return &spatial.Circle{X: center.X, Y: center.Y, R: conf.Radius * s * scale}
+	case colliderBox:
+		w, h := conf.Width, conf.Height
+		if w == 0 || h == 0 {
+			w, h = imgW, imgH
+		}
+		w, h = w/2, h/2
+		return spatial.Polygon{toStage(-w, -h), toStage(w, -h), toStage(w, h), toStage(-w, h)}
+	case colliderPolygon:
+		poly := make(spatial.Polygon, len(conf.Points))
+		for i, pt := range conf.Points {
+			poly[i] = toStage(pt.X, pt.Y)
+		}
+		return poly
+	}
+	return nil
+}
+
+func polygonOf(rRect *math32.RotatedRect) spatial.Polygon {
+	points := rRect.Points()
+	poly := make(spatial.Polygon, len(points))
+	for i, pt := range points {
+		poly[i] = spatial.Point{X: pt.X, Y: pt.Y}
+	}
+	return poly
+}
+
+// SetCollisionLayer sets the collision categories of the sprite: bit i of
+// layer stands for category i. A sprite with no category never collides.
+func (p *SpriteImpl) SetCollisionLayer(layer int) {
+	p.collisionLayer = uint32(layer)
+}
+
+func (p *SpriteImpl) CollisionLayer() int {
+	return int(p.collisionLayer)
+}
+
+// SetCollisionMask sets the collision categories the sprite collides with.
+// Two sprites collide only if each of them is in a category of the mask of
+// the other. For example, bullets in category 2 with the mask ^2 never test
+// against other bullets.
+func (p *SpriteImpl) SetCollisionMask(mask int) {
+	p.collisionMask = uint32(mask)
+}
+
+func (p *SpriteImpl) CollisionMask() int {
+	return int(p.collisionMask)
+}
+
+func (p *SpriteImpl) collidesWith(other *SpriteImpl) bool {
+	return p.collisionLayer&other.collisionMask != 0 && other.collisionLayer&p.collisionMask != 0
+}
+
+// collisionBounds returns the bounding rect of the collision shape of the
+// sprite, or of its costume if it collides by its pixels.
+func (p *SpriteImpl) collisionBounds() *math32.Rect {
+	rRect := p.getRotatedRect()
+	if rRect == nil {
+		return nil
+	}
+	if p.collisionShape != nil {
+		b := p.collisionShape.Box()
+		return math32.NewRect(b.MinX, b.MinY, b.MaxX-b.MinX, b.MaxY-b.MinY)
+	}
+	return rRect.BoundingRect()
+}
+
+// touchingShape checks if p touches dst, when at least one of them has a
+// collision shape.
+func (p *SpriteImpl) touchingShape(dst *SpriteImpl) bool {
+	s1, s2 := p.collisionShape, dst.collisionShape
+	if s1 != nil && s2 != nil {
+		return spatial.Overlap(s1, s2)
+	}
+	if s1 != nil {
+		return dst.touchesShape(s1)
+	}
+	return p.touchesShape(s2)
+}
+
+// touchesShape checks if p overlaps shape, which is in stage coordinates. A
+// sprite colliding by its pixels overlaps shape if shape contains any of its
+// non-transparent pixels.
+func (p *SpriteImpl) touchesShape(shape spatial.Shape) bool {
+	if p.collisionShape != nil {
+		return spatial.Overlap(p.collisionShape, shape)
+	}
+	b1, b2 := p.collisionBounds(), shape.Box()
+	if b1 == nil {
+		return false
+	}
+	boundRect := b1.Intersect(math32.NewRect(b2.MinX, b2.MinY, b2.MaxX-b2.MinX, b2.MaxY-b2.MinY))
+	if boundRect.Width <= 0 || boundRect.Height <= 0 {
+		return false
+	}
+
+	pixelAt := p.pixelReader()
+	for x := boundRect.X; x < boundRect.Width+boundRect.X; x++ {
+		for y := boundRect.Y; y < boundRect.Height+boundRect.Y; y++ {
+			if !shape.Contains(x, y) {
+				continue
+			}
+			color1 := pixelAt(math32.NewVector2(x, y))
+			if _, _, _, a := color1.RGBA(); a != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// -------------------------------------------------------------------------------------
@@ -71,13 +71,64 @@ type Config struct {
 }
 
 type cameraConfig struct {
-	On string `json:"on"`
+	On        string          `json:"on"`
+	DeadZone  *cameraDeadZone `json:"deadZone"`  // the followed object can move freely in this rect
+	Lerp      float64         `json:"lerp"`      // fraction of the distance moved per tick, 0 or 1 means no smoothing
+	LookAhead float64         `json:"lookAhead"` // distance to look ahead in the heading of the followed sprite
+	Mask      *uint32         `json:"mask"`      // render layers shown by the camera, all by default
+}
+
+type layerConfig struct {
+	Name    string `json:"name"`
+	YSort   bool   `json:"ysort"`
+	Default bool   `json:"default"` // layer of shapes without a specified layer
+}
+
+type physicsConfig struct {
+	Gravity *math32.Vector2 `json:"gravity"` // in pixels/s², (0, -980) by default
+}
+
+type bodyConfig struct {
+	Type         string         `json:"type"` // "static", "dynamic" or "kinematic"
+	Mass         float64        `json:"mass"` // 1 by default
+	Friction     *float64       `json:"friction"`
+	Restitution  float64        `json:"restitution"`
+	GravityScale *float64       `json:"gravityScale"` // 1 by default
+	Velocity     math32.Vector2 `json:"velocity"`
+}
+
+type viewportConfig struct {
+	cameraConfig
+	Name       string  `json:"name"`
+	X          float64 `json:"x"` // position and size in window pixels
+	Y          float64 `json:"y"`
+	Width      float64 `json:"width"`
+	Height     float64 `json:"height"`
+	Zoom       float64 `json:"zoom"`
+	Background string  `json:"background"`
+	Border     string  `json:"border"`
+	Visible    *bool   `json:"visible"`
+}
+
+type cameraDeadZone struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
 }
 
 type mapConfig struct {
-	Width  int    `json:"width"`
-	Height int    `json:"height"`
-	Mode   string `json:"mode"`
+	Width  int               `json:"width"`
+	Height int               `json:"height"`
+	Mode   string            `json:"mode"`
+	Layers []*mapLayerConfig `json:"layers"`
+}
+
+type mapLayerConfig struct {
+	Path     string          `json:"path"`
+	Backdrop string          `json:"backdrop"` // only shown with this backdrop, or with all if empty
+	Parallax *math32.Vector2 `json:"parallax"` // 1 moves with the world (default), 0 stays on screen
+	Repeat   string          `json:"repeat"`   // "x", "y", "xy" or "" (no repeat)
+	Offset   math32.Vector2  `json:"offset"`
+	Speed    math32.Vector2  `json:"speed"` // auto scrolling speed, in pixels per second
 }
 
 const (
@@ -105,6 +156,9 @@ type projConfig struct {
 	BackdropIndex *int              `json:"backdropIndex"`
 	Map           mapConfig         `json:"map"`
 	Camera        *cameraConfig     `json:"camera"`
+	Viewports     []*viewportConfig `json:"viewports"`
+	Layers        []*layerConfig    `json:"layers"`
+	Physics       *physicsConfig    `json:"physics"`
 	Run           *Config           `json:"run"`
 
 	// deprecated properties
@@ -170,13 +224,45 @@ type costumeMPSet struct {
 	Parts            []costumeSetPart `json:"parts"`
 }
 
-type costumeConfig struct {
-	Name             string  `json:"name"`
-	Path             string  `json:"path"`
-	X                float64 `json:"x"`
-	Y                float64 `json:"y"`
+// costumeAtlas is a sprite sheet exported by Aseprite or TexturePacker as a
+// JSON atlas. Its frames become costumes named by their file names without
+// extension, and its frame tags (or TexturePacker animations) become frame
+// animations, unless animations with the same names are defined.
+type costumeAtlas struct {
+	Path             string  `json:"path"`      // of the JSON file
 	FaceRight        float64 `json:"faceRight"` // turn face to right
 	BitmapResolution int     `json:"bitmapResolution"`
+	Fps              float64 `json:"fps"` // of frames without a duration, 25 by default
+}
+
+// skeletonConfig is a skeleton exported by Spine in JSON. Its animations can
+// be played like other animations of the sprite.
+type skeletonConfig struct {
+	Path   string `json:"path"`   // of the JSON file
+	Images string `json:"images"` // directory of images, the one of the skeleton file by default
+	Skin   string `json:"skin"`   // "default" by default
+}
+
+type costumeConfig struct {
+	Name             string          `json:"name"`
+	Path             string          `json:"path"`
+	X                float64         `json:"x"`
+	Y                float64         `json:"y"`
+	FaceRight        float64         `json:"faceRight"` // turn face to right
+	BitmapResolution int             `json:"bitmapResolution"`
+	Collider         *colliderConfig `json:"collider"` // collides by pixels if nil
+}
+
+// colliderConfig is the collision shape of a costume. Sizes and positions are
+// in costume pixels, relative to the costume center, with y pointing up.
+type colliderConfig struct {
+	Type   string           `json:"type"`   // "pixel" (default), "circle", "box" or "polygon"
+	Offset math32.Vector2   `json:"offset"` // center of the shape
+	Scale  float64          `json:"scale"`  // 1 by default
+	Radius float64          `json:"radius"` // of circle
+	Width  float64          `json:"width"`  // of box, the costume size by default
+	Height float64          `json:"height"`
+	Points []math32.Vector2 `json:"points"` // of convex polygon
 }
 type backdropConfig struct {
 	costumeConfig
@@ -192,6 +278,7 @@ const (
 	aniTypeMove
 	aniTypeTurn
 	aniTypeGlide
+	aniTypeSkeleton
 )
 
 type costumesConfig struct {
@@ -200,8 +287,17 @@ type costumesConfig struct {
 }
 
 type actionConfig struct {
-	Play     string          `json:"play"`     //play sound
-	Costumes *costumesConfig `json:"costumes"` //play frame
+	Play      string          `json:"play"`      //play sound
+	Costumes  *costumesConfig `json:"costumes"`  //play frame
+	Broadcast string          `json:"broadcast"` //broadcast a message
+	Clone     bool            `json:"clone"`     //clone the sprite
+}
+
+// aniEventConfig is an action done when an animation reaches a frame, like
+// {"frame": 3, "play": "step"}. Frames count from 0.
+type aniEventConfig struct {
+	Frame int `json:"frame"`
+	actionConfig
 }
 
 type aniConfig struct {
@@ -215,13 +311,51 @@ type aniConfig struct {
 	StepDuration   float64     `json:"stepDuration"`
 	TurnToDuration float64     `json:"turnToDuration"`
 
-	AniType      aniTypeEnum   `json:"anitype"`
-	OnStart      *actionConfig `json:"onStart"` //start
-	OnPlay       *actionConfig `json:"onPlay"`  //play
-	IsLoop       bool          `json:"isLoop"`
-	IsKeepOnStop bool          `json:"isKeepOnStop"` //After finishing playback, it stays on the last frame and does not need to switch to the default animation
+	AniType      aniTypeEnum       `json:"anitype"`
+	OnStart      *actionConfig     `json:"onStart"` //start
+	OnPlay       *actionConfig     `json:"onPlay"`  //play
+	OnEnd        *actionConfig     `json:"onEnd"`   //end, unless stopped or replaced
+	IsLoop       bool              `json:"isLoop"`
+	IsKeepOnStop bool              `json:"isKeepOnStop"` //After finishing playback, it stays on the last frame and does not need to switch to the default animation
+	Easing       string            `json:"easing"`       // like "easeInOutQuad" or "cubic-bezier(0.25, 0.1, 0.25, 1)", linear by default
+	Events       []*aniEventConfig `json:"events"`       // actions at frames
+
+	easing Easing     // parsed from Easing, or set by scripts
+	frames []aniFrame // frames with their durations, of animations from sprite sheet tags
+	path   Path       // move animations along a path move by distances along it
+}
+
+// aniFrame is a frame of an animation with per-frame durations.
+type aniFrame struct {
+	costume int
+	ms      int // duration in milliseconds
+}
+
+type animatorConfig struct {
+	Parameters  map[string]interface{}      `json:"parameters"` // name => initial bool or float, or "trigger"
+	States      []*animatorStateConfig      `json:"states"`
+	Default     string                      `json:"default"` // the first state by default
+	Transitions []*animatorTransitionConfig `json:"transitions"`
+}
+
+type animatorStateConfig struct {
+	Name      string  `json:"name"`
+	Animation string  `json:"animation"` // the state name by default
+	Loop      *bool   `json:"loop"`      // the isLoop of the animation by default
+	Speed     float64 `json:"speed"`     // 1 by default
+}
+
+type animatorTransitionConfig struct {
+	From       string                     `json:"from"` // "any" for any state
+	To         string                     `json:"to"`
+	Conditions []*animatorConditionConfig `json:"conditions"`
+	ExitTime   *float64                   `json:"exitTime"` // progress of the animation of the from state, 0..1
+}
 
-	//OnEnd *actionConfig  `json:"onEnd"`   //stop
+type animatorConditionConfig struct {
+	Param string      `json:"param"`
+	Op    string      `json:"op"`    // "==" (default), "!=", ">", ">=", "<" or "<="
+	Value interface{} `json:"value"` // bool or float, true by default
 }
 
 // -------------------------------------------------------------------------------------
@@ -235,6 +369,8 @@ type spriteConfig struct {
 	Costumes            []*costumeConfig      `json:"costumes"`
 	CostumeSet          *costumeSet           `json:"costumeSet"`
 	CostumeMPSet        *costumeMPSet         `json:"costumeMPSet"`
+	CostumeAtlas        *costumeAtlas         `json:"costumeAtlas"`
+	Skeleton            *skeletonConfig       `json:"skeleton"` // drawn instead of costumes
 	CurrentCostumeIndex *int                  `json:"currentCostumeIndex"`
 	CostumeIndex        int                   `json:"costumeIndex"`
 	FAnimations         map[string]*aniConfig `json:"fAnimations"`
@@ -245,6 +381,13 @@ type spriteConfig struct {
 	Pivot               math32.Vector2        `json:"pivot"`
 	DefaultAnimation    string                `json:"defaultAnimation"`
 	AnimBindings        map[string]string     `json:"animBindings"`
+	RenderLayer         int                   `json:"renderLayer"`
+	Layer               string                `json:"layer"`
+	Collider            *colliderConfig       `json:"collider"`       // default collider of costumes
+	CollisionLayer      *uint32               `json:"collisionLayer"` // collision categories, 1 by default
+	CollisionMask       *uint32               `json:"collisionMask"`  // categories collided with, all by default
+	Body                *bodyConfig           `json:"body"`           // rigid body of the physics world
+	Animator            *animatorConfig       `json:"animator"`
 }
 
 func (p *spriteConfig) getCostumeIndex() int {
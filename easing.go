@@ -0,0 +1,175 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goplus/spx/internal/tools"
+)
+
+// Easing specifies how the rate of change of an animation varies over time.
+type Easing int
+
+const (
+	Linear Easing = iota
+	EaseInCircle
+	EaseOutCircle
+	EaseInOutCircle
+	EaseInBack
+	EaseOutBack
+	EaseInOutBack
+	EaseInBounce
+	EaseOutBounce
+	EaseInOutBounce
+	EaseInQuad
+	EaseOutQuad
+	EaseInOutQuad
+	EaseInCubic
+	EaseOutCubic
+	EaseInOutCubic
+	EaseInQuart
+	EaseOutQuart
+	EaseInOutQuart
+	EaseInQuint
+	EaseOutQuint
+	EaseInOutQuint
+	EaseInSine
+	EaseOutSine
+	EaseInOutSine
+	EaseInExpo
+	EaseOutExpo
+	EaseInOutExpo
+	EaseInElastic
+	EaseOutElastic
+	EaseInOutElastic
+
+	lastNamedEasing = EaseInOutElastic
+)
+
+var easingNames = [...]string{"Circle", "Back", "Bounce", "Quad", "Cubic", "Quart", "Quint", "Sine", "Expo", "Elastic"}
+var easingModes = [...]string{"easeIn", "easeOut", "easeInOut"}
+
+// bezierEasings are the curves created by CubicBezier, their easings follow
+// the named ones.
+var (
+	bezierEasings []*tools.BezierCurveEase
+	bezierMutex   sync.Mutex
+)
+
+// CubicBezier returns an easing following a CSS-like cubic-bezier(x1, y1,
+// x2, y2) curve. x1 and x2 must be in 0..1.
+func CubicBezier(x1, y1, x2, y2 float64) Easing {
+	if x1 < 0 || x1 > 1 || x2 < 0 || x2 > 1 {
+		log.Println("CubicBezier: x1 and x2 must be in 0..1 -", x1, x2)
+		return Linear
+	}
+	bezierMutex.Lock()
+	defer bezierMutex.Unlock()
+	for i, b := range bezierEasings {
+		if b.X1 == x1 && b.Y1 == y1 && b.X2 == x2 && b.Y2 == y2 {
+			return lastNamedEasing + 1 + Easing(i)
+		}
+	}
+	bezierEasings = append(bezierEasings, tools.NewBezierCurveEase(x1, y1, x2, y2))
+	return lastNamedEasing + Easing(len(bezierEasings))
+}
+
+// parseEasing parses the name of an easing, like "linear", "easeInOutQuad",
+// or "cubic-bezier(0.25, 0.1, 0.25, 1)".
+func parseEasing(name string) (Easing, bool) {
+	if name == "" || name == "linear" {
+		return Linear, true
+	}
+	if args := strings.TrimPrefix(name, "cubic-bezier("); args != name && strings.HasSuffix(args, ")") {
+		parts := strings.Split(strings.TrimSuffix(args, ")"), ",")
+		if len(parts) != 4 {
+			return Linear, false
+		}
+		var v [4]float64
+		for i, part := range parts {
+			f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return Linear, false
+			}
+			v[i] = f
+		}
+		return CubicBezier(v[0], v[1], v[2], v[3]), true
+	}
+	for i, ease := range easingNames {
+		for j, mode := range easingModes {
+			if name == mode+ease {
+				return Easing(1 + i*len(easingModes) + j), true
+			}
+		}
+	}
+	return Linear, false
+}
+
+// function returns the easing function of e, or nil for Linear.
+func (e Easing) function() tools.IEasingFunction {
+	if e > lastNamedEasing {
+		bezierMutex.Lock()
+		defer bezierMutex.Unlock()
+		if i := int(e - lastNamedEasing - 1); i < len(bezierEasings) {
+			return bezierEasings[i]
+		}
+		return nil
+	}
+	if e <= Linear {
+		return nil
+	}
+	var fn interface {
+		tools.IEasingFunction
+		SetEasingMode(mode tools.EasingMode)
+	}
+	switch (e - 1) / 3 {
+	case 0:
+		fn = tools.NewCircleEase()
+	case 1:
+		fn = tools.NewBackEase()
+	case 2:
+		fn = tools.NewBounceEase()
+	case 3:
+		fn = tools.NewQuadraticEase()
+	case 4:
+		fn = tools.NewCubicEase()
+	case 5:
+		fn = tools.NewQuarticEase()
+	case 6:
+		fn = tools.NewQuinticEase()
+	case 7:
+		fn = tools.NewSineEase()
+	case 8:
+		fn = tools.NewExponentialEase()
+	default:
+		fn = tools.NewElasticEase()
+	}
+	fn.SetEasingMode(tools.EasingMode((e - 1) % 3))
+	return fn
+}
+
+// ease maps the linear progress t (0..1) through e.
+func (e Easing) ease(t float64) float64 {
+	if fn := e.function(); fn != nil {
+		return fn.Ease(fn, t)
+	}
+	return t
+}
@@ -0,0 +1,90 @@
+package spx
+
+import (
+	"math"
+	"testing"
+
+	"github.com/goplus/spx/internal/tools"
+)
+
+func TestParseEasing(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		e    Easing
+		ok   bool
+	}{
+		{"", Linear, true},
+		{"linear", Linear, true},
+		{"easeInCircle", EaseInCircle, true},
+		{"easeOutBounce", EaseOutBounce, true},
+		{"easeInOutQuad", EaseInOutQuad, true},
+		{"easeInOutElastic", EaseInOutElastic, true},
+		{"easeIn", Linear, false},
+		{"EaseInQuad", Linear, false},
+		{"bounce", Linear, false},
+		{"cubic-bezier(0.25, 0.1)", Linear, false},
+		{"cubic-bezier(0.25, a, 0.25, 1)", Linear, false},
+		{"cubic-bezier(0.25, 0.1, 0.25, 1", Linear, false},
+	} {
+		if e, ok := parseEasing(tc.name); e != tc.e || ok != tc.ok {
+			t.Fatal("parseEasing:", tc.name, e, ok)
+		}
+	}
+}
+
+func TestParseCubicBezier(t *testing.T) {
+	e, ok := parseEasing("cubic-bezier(0.25, 0.1, 0.25, 1)")
+	if !ok || e <= lastNamedEasing {
+		t.Fatal("parseEasing:", e, ok)
+	}
+	if e2 := CubicBezier(0.25, 0.1, 0.25, 1); e2 != e {
+		t.Fatal("CubicBezier isn't reused:", e, e2)
+	}
+	if y := e.ease(0.5); math.Abs(y-0.8024034) > 1e-5 {
+		t.Fatal("ease:", y)
+	}
+	if e, ok := parseEasing("cubic-bezier(2, 0, 0, 1)"); !ok || e != Linear {
+		t.Fatal("invalid x1:", e, ok)
+	}
+}
+
+func TestEasingFunction(t *testing.T) {
+	if Linear.function() != nil {
+		t.Fatal("Linear has a function")
+	}
+	if y := Linear.ease(0.3); y != 0.3 {
+		t.Fatal("Linear.ease:", y)
+	}
+	quad := func(x float64) float64 { return x * x }
+	for _, tc := range []struct {
+		e    Easing
+		x, y float64
+	}{
+		{EaseInQuad, 0.25, quad(0.25)},
+		{EaseOutQuad, 0.25, 1 - quad(0.75)},
+		{EaseInOutQuad, 0.25, quad(0.5) / 2},
+		{EaseInOutQuad, 0.75, 1 - quad(0.5)/2},
+	} {
+		if y := tc.e.ease(tc.x); math.Abs(y-tc.y) > 1e-9 {
+			t.Fatal("ease:", tc.e, tc.x, y, tc.y)
+		}
+	}
+	for e := EaseInCircle; e <= lastNamedEasing; e++ {
+		fn := e.function()
+		if fn == nil {
+			t.Fatal("no function:", e)
+		}
+		if y := fn.Ease(fn, 0); math.Abs(y) > 1e-9 {
+			t.Fatal("start:", e, y)
+		}
+		if y := fn.Ease(fn, 1); math.Abs(y-1) > 1e-9 {
+			t.Fatal("end:", e, y)
+		}
+	}
+	if _, ok := EaseInOutSine.function().(*tools.SineEase); !ok {
+		t.Fatal("EaseInOutSine isn't a sine")
+	}
+	if _, ok := EaseOutElastic.function().(*tools.ElasticEase); !ok {
+		t.Fatal("EaseOutElastic isn't elastic")
+	}
+}
@@ -100,6 +100,8 @@ type eventSinkMgr struct {
 	allWhenClick           *eventSink
 	allWhenMoving          *eventSink
 	allWhenTurning         *eventSink
+	allWhenAnimationEnd    *eventSink
+	allWhenAnimationFrame  *eventSink
 	calledStart            bool
 }
 
@@ -115,6 +117,8 @@ func (p *eventSinkMgr) reset() {
 	p.allWhenClick = nil
 	p.allWhenMoving = nil
 	p.allWhenTurning = nil
+	p.allWhenAnimationEnd = nil
+	p.allWhenAnimationFrame = nil
 	p.calledStart = false
 }
 
@@ -130,6 +134,8 @@ func (p *eventSinkMgr) doDeleteClone(this interface{}) {
 	p.allWhenClick = p.allWhenClick.doDeleteClone(this)
 	p.allWhenMoving = p.allWhenMoving.doDeleteClone(this)
 	p.allWhenTurning = p.allWhenTurning.doDeleteClone(this)
+	p.allWhenAnimationEnd = p.allWhenAnimationEnd.doDeleteClone(this)
+	p.allWhenAnimationFrame = p.allWhenAnimationFrame.doDeleteClone(this)
 }
 
 func (p *eventSinkMgr) doWhenStart() {
@@ -207,6 +213,32 @@ func (p *eventSinkMgr) doWhenTurning(this threadObj, mi *TurningInfo) {
 	})
 }
 
+func (p *eventSinkMgr) doWhenAnimationEnd(this threadObj, name SpriteAnimationName) {
+	p.allWhenAnimationEnd.asyncCall(false, this, func(ev *eventSink) {
+		if debugEvent {
+			log.Println("==> onAnimationEnd", nameOf(this), name)
+		}
+		ev.sink.(func(SpriteAnimationName))(name)
+	})
+}
+
+// animationFrame is the data of the events of animation frames, which sinks
+// compare to the frame they listen to.
+type animationFrame struct {
+	this  threadObj
+	name  SpriteAnimationName
+	frame int
+}
+
+func (p *eventSinkMgr) doWhenAnimationFrame(this threadObj, name SpriteAnimationName, frame int) {
+	p.allWhenAnimationFrame.asyncCall(false, animationFrame{this, name, frame}, func(ev *eventSink) {
+		if debugEvent {
+			log.Println("==> onAnimationFrame", nameOf(this), name, frame)
+		}
+		ev.sink.(func())()
+	})
+}
+
 func (p *eventSinkMgr) doWhenIReceive(msg string, data interface{}, wait bool) {
 	p.allWhenIReceive.call(wait, msg, func(ev *eventSink) {
 		ev.sink.(func(string, interface{}))(msg, data)
@@ -246,6 +278,9 @@ func nameOf(this interface{}) string {
 	if _, ok := this.(*Game); ok {
 		return "Game"
 	}
+	if w, ok := this.(Widget); ok {
+		return w.GetName()
+	}
 	panic("eventSinks: unexpected this object")
 }
 
@@ -34,7 +34,7 @@ import (
 	"github.com/goplus/spx/internal/audiorecord"
 	"github.com/goplus/spx/internal/coroutine"
 	"github.com/goplus/spx/internal/gdi"
-	"github.com/goplus/spx/internal/math32"
+	"github.com/goplus/spx/internal/spatial"
 	"github.com/hajimehoshi/ebiten/v2"
 
 	spxfs "github.com/goplus/spx/fs"
@@ -78,6 +78,8 @@ type Game struct {
 	eventSinks
 	Camera
 
+	viewports []*Viewport // additional cameras, drawn on top of the main one
+
 	fs     spxfs.Dir
 	shared *sharedImages
 
@@ -87,6 +89,16 @@ type Game struct {
 	sprs   map[string]Sprite       // map: name => sprite prototype, for loaded sprites
 	items  []Shape                 // shapes on stage (in Zorder), not only sprites
 
+	broadphase spriteGrid // rebuilt by updateColliders
+	queryGrid  spriteGrid // rebuilt by queries when queryDirty is set
+	queryDirty bool       // sprites moved or added since queryGrid was built
+	physics    physicsWorld
+	navGrid    *NavGrid // see SetNavGrid
+
+	zLayers       []zLayer      // z-order layers, items are sorted by their layers
+	defaultZLayer int           // layer of shapes without a specified layer
+	shapeZLayers  map[Shape]int // layers of shapes other than sprites
+
 	tickMgr tickMgr
 	input   inputMgr
 	events  chan event
@@ -96,14 +108,19 @@ type Game struct {
 	worldWidth_  int
 	worldHeight_ int
 	mapMode      int
+	mapLayers    []*mapLayer // parallax layers drawn on top of the backdrop
 	world        *ebiten.Image
 
 	// window
 	windowWidth_  int
 	windowHeight_ int
+	layoutWidth_  int // window size of the last layout
+	layoutHeight_ int
 
 	gMouseX, gMouseY int64
 
+	focused *TextField // the text field which receives keyboard input
+
 	sinkMgr  eventSinkMgr
 	isLoaded bool
 	isRunned bool
@@ -159,6 +176,11 @@ func (p *Game) reset() {
 	p.input.reset()
 	p.Stop(AllOtherScripts)
 	p.items = nil
+	p.viewports = nil
+	p.physics.reset()
+	p.navGrid = nil
+	p.focused = nil
+	p.layoutWidth_, p.layoutHeight_ = 0, 0
 	p.isLoaded = false
 	p.sprs = make(map[string]Sprite)
 }
@@ -170,6 +192,7 @@ func (p *Game) getGame() *Game {
 func (p *Game) initGame(sprites []Sprite) *Game {
 	p.tickMgr.init()
 	p.eventSinks.init(&p.sinkMgr, p)
+	p.initZLayers(nil)
 	p.sprs = make(map[string]Sprite)
 	p.typs = make(map[string]reflect.Type)
 	for _, spr := range sprites {
@@ -252,6 +275,7 @@ func Gopt_Game_Run(game Gamer, resource interface{}, gameConf ...*Config) {
 		log.Println("==> StartLoad", resource)
 	}
 	g.startLoad(fs, &conf)
+	g.initZLayers(proj.Layers) // sprites need layers to load
 	for i, n := 0, v.NumField(); i < n; i++ {
 		name, val := getFieldPtrOrAlloc(g, v, i)
 		switch fld := val.(type) {
@@ -436,6 +460,7 @@ func (p *Game) loadIndex(g reflect.Value, proj *projConfig) (err error) {
 	}
 	p.world = ebiten.NewImage(p.worldWidth_, p.worldHeight_)
 	p.mapMode = toMapMode(proj.Map.Mode)
+	p.initMapLayers(proj.Map.Layers)
 
 	inits := make([]Sprite, 0, len(proj.Zorder))
 	for _, v := range proj.Zorder {
@@ -472,9 +497,11 @@ func (p *Game) loadIndex(g reflect.Value, proj *projConfig) (err error) {
 	p.Camera.init(p, float64(p.windowWidth_), float64(p.windowHeight_), float64(p.worldWidth_), float64(p.worldHeight_))
 
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeOnlyFullscreenEnabled)
-	if proj.Camera != nil && proj.Camera.On != "" {
-		p.Camera.On__2(proj.Camera.On)
+	if proj.Camera != nil {
+		p.Camera.initWith(proj.Camera)
 	}
+	p.initViewports(proj.Viewports)
+	p.physics.init(proj.Physics)
 	if loader, ok := g.Addr().Interface().(interface{ OnLoaded() }); ok {
 		loader.OnLoaded()
 	}
@@ -494,6 +521,11 @@ func Gopt_Game_Reload(game Gamer, index interface{}) (err error) {
 	v := reflect.ValueOf(game).Elem()
 	g := instance(v)
 	g.reset()
+	var proj projConfig
+	if err = loadProjConfig(&proj, g.fs, index); err != nil {
+		return
+	}
+	g.initZLayers(proj.Layers) // sprites need layers to load
 	for i, n := 0, v.NumField(); i < n; i++ {
 		name, val := getFieldPtrOrAlloc(g, v, i)
 		if fld, ok := val.(Sprite); ok {
@@ -502,10 +534,6 @@ func Gopt_Game_Reload(game Gamer, index interface{}) (err error) {
 			}
 		}
 	}
-	var proj projConfig
-	if err = loadProjConfig(&proj, g.fs, index); err != nil {
-		return
-	}
 	return g.loadIndex(v, &proj)
 }
 
@@ -514,14 +542,37 @@ func Gopt_Game_Reload(game Gamer, index interface{}) (err error) {
 type specsp = map[string]interface{}
 
 func (p *Game) addSpecialShape(g reflect.Value, v specsp, inits []Sprite) []Sprite {
+	var shape Shape
 	switch typ := v["type"].(string); typ {
 	case "stageMonitor", "monitor":
-		if sm, err := newMonitor(g, v); err == nil {
-			sm.game = p
-			p.addShape(sm)
+		sm, err := newMonitor(g, v)
+		if err != nil {
+			return inits
 		}
+		sm.game = p
+		shape = sm
 	case "measure":
-		p.addShape(newMeasure(v))
+		shape = newMeasure(v)
+	case "button":
+		shape = newButton(p, v)
+	case "label":
+		shape = newLabel(p, v)
+	case "panel":
+		shape = newPanel(p, v)
+	case "textField":
+		shape = newTextField(p, v)
+	case "checkbox":
+		shape = newCheckbox(p, v)
+	case "progressBar":
+		shape = newProgressBar(p, v)
+	case "tilemap":
+		tm, err := newTilemap(p, v)
+		if err != nil {
+			return inits
+		}
+		shape = tm
+	case "particles":
+		shape = newParticlesFrom(p, v)
 	case "sprites":
 		return p.addStageSprites(g, v, inits)
 	case "sprite":
@@ -529,6 +580,10 @@ func (p *Game) addSpecialShape(g reflect.Value, v specsp, inits []Sprite) []Spri
 	default:
 		panic("addSpecialShape: unknown shape - " + typ)
 	}
+	if layer, ok := v["layer"].(string); ok {
+		p.shapeZLayers[shape] = p.zLayerIndex(layer)
+	}
+	p.addShape(shape)
 	return inits
 }
 
@@ -622,7 +677,12 @@ func (p *Game) runLoop(cfg *Config) (err error) {
 }
 
 func (p *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	return p.windowSize_()
+	screenWidth, screenHeight = p.windowSize_()
+	if screenWidth != p.layoutWidth_ || screenHeight != p.layoutHeight_ {
+		p.layoutWidth_, p.layoutHeight_ = screenWidth, screenHeight
+		p.doLayout(screenWidth, screenHeight)
+	}
+	return
 }
 
 func (p *Game) Update() error {
@@ -630,42 +690,132 @@ func (p *Game) Update() error {
 		return nil
 	}
 
-	p.updateColliders()
+	p.updateWorld()
 	p.input.update()
+	p.updateFocus()
 	p.updateMousePos()
 	p.sounds.update()
 	p.tickMgr.update()
+	p.updateAnimators()
+	p.ySortZLayers()
+	p.updateMapLayers()
+	p.updateParticles()
+	p.updateTrails()
+	p.Camera.update()
+	for _, vp := range p.viewports {
+		vp.update()
+	}
 	return nil
 }
 
+// updateWorld steps the physics world and updates the touching sprites in a
+// coroutine, like the tick handlers: the physics step moves sprites and fires
+// their events, which must not run concurrently with scripts.
+func (p *Game) updateWorld() {
+	gco.CreateAndStart(true, nil, func(me coroutine.Thread) int {
+		p.updatePhysics()
+		p.updateColliders()
+		return 0
+	})
+}
+
+// broadphaseMinCellSize is the min cell size of the broadphase grid.
+const broadphaseMinCellSize = 32.0
+
 func (p *Game) updateColliders() {
-	var startTime time.Time
+	var startTime, narrowTime time.Time
 	if debugPerf {
 		startTime = time.Now()
 	}
 
-	items := p.items
-	n := len(items)
-	for i := 0; i < n; i++ {
-		s1, ok1 := items[i].(*SpriteImpl)
-		if ok1 {
-			flag := s1.isVisible && !s1.isDying
-			for j := i + 1; j < n; j++ {
-				s2, ok2 := items[j].(*SpriteImpl)
-				if ok2 && s1 != s2 {
-					flag2 := flag && s2.isVisible && !s2.isDying && s1.touchingSprite(s2)
-					s1.collider.SetTouching(s2, flag2)
-					s2.collider.SetTouching(s1, flag2)
+	p.broadphase.build(p.items)
+	sprites := p.broadphase.sprites
+	touching := make(map[[2]*SpriteImpl]bool)
+	if debugPerf {
+		narrowTime = time.Now()
+	}
+	candidates := 0
+	p.broadphase.grid.Pairs(func(a, b int) {
+		s1, s2 := sprites[a], sprites[b]
+		if !s1.collidesWith(s2) {
+			return
+		}
+		candidates++
+		if s1.touchingSprite(s2) {
+			touching[[2]*SpriteImpl{s1, s2}] = true
+			s1.collider.SetTouching(s2, true)
+			s2.collider.SetTouching(s1, true)
+		}
+	})
+
+	// sprites in contact in the physics world are touching, even if they
+	// were separated by the solver
+	for pair := range p.physics.contacts {
+		s1, s2 := pair[0], pair[1]
+		if !touching[pair] && !touching[[2]*SpriteImpl{s2, s1}] {
+			touching[pair] = true
+			s1.collider.SetTouching(s2, true)
+			s2.collider.SetTouching(s1, true)
+		}
+	}
+
+	// end touching of pairs which are not touching anymore
+	for _, item := range p.items {
+		if s1, ok := item.(*SpriteImpl); ok {
+			for _, s2 := range s1.collider.touchingSprites() {
+				if !touching[[2]*SpriteImpl{s1, s2}] && !touching[[2]*SpriteImpl{s2, s1}] {
+					s1.collider.SetTouching(s2, false)
+					s2.collider.SetTouching(s1, false)
 				}
 			}
 		}
 	}
 
 	if debugPerf {
-		log.Println("updateColliders shapes:", n, " cost:", time.Now().Sub(startTime))
+		now := time.Now()
+		log.Println("updateColliders shapes:", len(p.items), "sprites:", len(sprites),
+			"candidates:", candidates, "touching:", len(touching),
+			"broadphase:", narrowTime.Sub(startTime), "narrowphase:", now.Sub(narrowTime))
 	}
 }
 
+// spriteGrid is a grid of the bounds of visible sprites.
+type spriteGrid struct {
+	grid    *spatial.Grid
+	sprites []*SpriteImpl // sprites by their ids in grid
+}
+
+// build inserts the bounds of all visible sprites of items into the grid.
+func (p *spriteGrid) build(items []Shape) {
+	sprites := p.sprites[:0]
+	boxes := make([]spatial.Box, 0, len(items))
+	size := 0.0
+	for _, item := range items {
+		if sp, ok := item.(*SpriteImpl); ok && sp.isVisible && !sp.isDying {
+			rc := sp.collisionBounds()
+			if rc == nil {
+				continue
+			}
+			boxes = append(boxes, spatial.Box{MinX: rc.X, MinY: rc.Y, MaxX: rc.X + rc.Width, MaxY: rc.Y + rc.Height})
+			sprites = append(sprites, sp)
+			size += math.Max(rc.Width, rc.Height)
+		}
+	}
+	cellSize := broadphaseMinCellSize
+	if n := len(sprites); n > 0 {
+		cellSize = math.Max(size/float64(n)*2, cellSize) // about twice as large as sprites
+	}
+	if p.grid == nil {
+		p.grid = spatial.NewGrid(cellSize)
+	} else {
+		p.grid.Reset(cellSize)
+	}
+	for _, box := range boxes {
+		p.grid.Insert(box)
+	}
+	p.sprites = sprites
+}
+
 // startTick creates tickHandler to handle `onTick` event.
 // You can call tickHandler.Stop to stop listening `onTick` event.
 func (p *Game) startTick(duration int64, onTick func(tick int64)) *tickHandler {
@@ -680,8 +830,20 @@ func (p *Game) currentTPS() float64 {
 
 func (p *Game) Draw(screen *ebiten.Image) {
 	dc := drawContext{Image: p.world}
-	p.onDraw(dc)
-	p.Camera.render(dc.Image, screen)
+	cam := &p.Camera
+	p.onDraw(dc, cam)
+	cam.render(dc.Image, screen)
+	for _, vp := range p.viewports {
+		if !vp.visible {
+			continue
+		}
+		if vp.layerMask != cam.layerMask { // the world is drawn with other layers
+			p.onDraw(dc, &vp.Camera)
+		}
+		cam = &vp.Camera
+		vp.render(dc.Image, screen)
+	}
+	p.onDrawHUD(drawContext{Image: screen})
 }
 
 type clicker interface {
@@ -692,6 +854,9 @@ type clicker interface {
 func (p *Game) doWhenLeftButtonDown(ev *eventLeftButtonDown) {
 	hc := hitContext{Pos: image.Pt(ev.X, ev.Y)}
 	if hr, ok := p.onHit(hc); ok {
+		if tf, ok := hr.Target.(*TextField); !ok || tf != p.focused {
+			p.setFocus(nil)
+		}
 		if o, ok := hr.Target.(clicker); ok {
 			o.doWhenClick(o)
 		}
@@ -705,7 +870,9 @@ func (p *Game) handleEvent(event event) {
 		p.updateMousePos()
 		p.doWhenLeftButtonDown(ev)
 	case *eventKeyDown:
-		p.sinkMgr.doWhenKeyPressed(ev.Key)
+		if !p.typing() {
+			p.sinkMgr.doWhenKeyPressed(ev.Key)
+		}
 	case *eventStart:
 		p.sinkMgr.doWhenStart()
 	}
@@ -899,24 +1066,30 @@ func (p *Game) objectPos(obj interface{}) (float64, float64) {
 
 // -----------------------------------------------------------------------------
 
-func (p *Game) getTurtle() turtleCanvas {
-	return p.turtle
+func (p *Game) getTurtle() *turtleCanvas {
+	return &p.turtle
 }
 
-func (p *Game) stampCostume(di *spriteDrawInfo) {
-	p.turtle.stampCostume(di)
+func (p *Game) stampCostume(st *penStamp) {
+	p.turtle.stampCostume(st)
 }
 
 func (p *Game) movePen(sp *SpriteImpl, x, y float64) {
 	worldW, worldH := p.worldSize_()
+	ox, oy := float64(worldW)/2, float64(worldH)/2
 	p.turtle.penLine(&penLine{
-		x1:    (worldW >> 1) + int(sp.x),
-		y1:    (worldH >> 1) - int(sp.y),
-		x2:    (worldW >> 1) + int(x),
-		y2:    (worldH >> 1) - int(y),
-		clr:   sp.penColor,
-		width: int(sp.penWidth),
+		x1:     ox + sp.x,
+		y1:     oy - sp.y,
+		x2:     ox + x,
+		y2:     oy - y,
+		clr:    sp.penColor,
+		width:  sp.penWidth,
+		cap:    sp.penCap,
+		dash:   sp.penDash,
+		gap:    sp.penGap,
+		offset: sp.penDashOffset,
 	})
+	sp.penDashOffset += math.Hypot(x-sp.x, y-sp.y)
 }
 
 func (p *Game) EraseAll() {
@@ -929,11 +1102,25 @@ func (p *Game) getItems() []Shape {
 	return p.items
 }
 
+// addShape adds child to the front of its layer.
 func (p *Game) addShape(child Shape) {
-	p.items = append(p.items, child)
+	p.queryDirty = true
+	items := p.items
+	_, to := p.zLayerRange(items, p.zLayerOf(child))
+	if to == len(items) {
+		p.items = append(items, child)
+		return
+	}
+	// getItems() requires immutable items, so we need copy before modify
+	newItems := make([]Shape, len(items)+1)
+	copy(newItems, items[:to])
+	copy(newItems[to+1:], items[to:])
+	newItems[to] = child
+	p.items = newItems
 }
 
 func (p *Game) addClonedShape(src, clone Shape) {
+	p.queryDirty = true
 	items := p.items
 	idx := p.doFindSprite(src)
 	if idx < 0 {
@@ -965,18 +1152,21 @@ func (p *Game) removeShape(child Shape) {
 	}
 }
 
+// activateShape moves child to the front of its layer.
 func (p *Game) activateShape(child Shape) {
 	items := p.items
+	_, to := p.zLayerRange(items, p.zLayerOf(child))
 	for i, item := range items {
 		if item == child {
-			if i == 0 {
+			if i == 0 || i == to-1 {
 				return
 			}
 			// getItems() requires immutable items, so we need copy before modify
 			newItems := make([]Shape, len(items))
 			copy(newItems, items[:i])
-			copy(newItems[i:], items[i+1:])
-			newItems[len(items)-1] = child
+			copy(newItems[i:to-1], items[i+1:to])
+			copy(newItems[to:], items[to:])
+			newItems[to-1] = child
 			p.items = newItems
 			return
 		}
@@ -989,9 +1179,10 @@ func (p *Game) goBackByLayers(spr *SpriteImpl, n int) {
 		return
 	}
 	items := p.items
+	from, to := p.zLayerRange(items, spr.zLayer) // stay within the layer
 	if n > 0 {
 		newIdx := idx
-		for newIdx > 0 {
+		for newIdx > from {
 			newIdx--
 			item := items[newIdx]
 			if _, ok := item.(*SpriteImpl); ok {
@@ -1012,7 +1203,7 @@ func (p *Game) goBackByLayers(spr *SpriteImpl, n int) {
 		}
 	} else if n < 0 {
 		newIdx := idx
-		lastIdx := len(items) - 1
+		lastIdx := to - 1
 		if newIdx < lastIdx {
 			for {
 				newIdx++
@@ -1071,14 +1262,13 @@ func (p *Game) drawBackground(dc drawContext) {
 		bgImage := img.Ebiten()
 		imgW := float64(img.Bounds().Dx())
 		imgH := float64(img.Bounds().Dy())
-		winW := float64(p.windowWidth_)
-		winH := float64(p.windowHeight_)
+		viewSize := p.Camera.viewSize() // zoomed out or rotated cameras see more tiles
+		winW := math.Max(viewSize.X, float64(p.windowWidth_))
+		winH := math.Max(viewSize.Y, float64(p.windowHeight_))
 		numW := int(math.Ceil(winW/imgW/2 - 0.5))
 		numH := int(math.Ceil(winH/imgH/2 - 0.5))
-		rawOffsetW := float64(p.worldWidth_-p.windowWidth_) / 2.0
-		rawOffsetH := float64(p.worldHeight_-p.windowHeight_) / 2.0
-		offsetW := rawOffsetW + winW*0.5 - imgW*0.5 // draw from center
-		offsetH := rawOffsetH + winH*0.5 - imgH*0.5
+		offsetW := float64(p.worldWidth_)*0.5 - imgW*0.5 // draw from center
+		offsetH := float64(p.worldHeight_)*0.5 - imgH*0.5
 		for w := -numW; w <= numW; w++ {
 			for h := -numH; h <= numH; h++ {
 				op := &ebiten.DrawImageOptions{}
@@ -1088,42 +1278,10 @@ func (p *Game) drawBackground(dc drawContext) {
 		}
 
 	} else {
-		var imgW, imgH, dstW, dstH float32
-		imgW = float32(img.Bounds().Dx())
-		imgH = float32(img.Bounds().Dy())
-		worldW := float32(p.worldWidth_)
-		worldH := float32(p.worldHeight_)
-
+		imgW := float32(img.Bounds().Dx())
+		imgH := float32(img.Bounds().Dy())
 		options.Address = ebiten.AddressClampToZero
-		imgRadio := (imgW / imgH)
-		worldRadio := (worldW / worldH)
-		// scale image's height to fit world's height
-		isScaleHeight := imgRadio > worldRadio
-		switch p.mapMode {
-		default:
-			dstW = worldW
-			dstH = worldH
-		case mapModeFillCut:
-			if isScaleHeight {
-				dstW = worldW
-				dstH = dstW / imgRadio
-			} else {
-				dstH = worldH
-				dstW = dstH * imgRadio
-			}
-		case mapModeFillRatio:
-			if isScaleHeight {
-				dstH = worldH
-				dstW = dstH * imgRadio
-			} else {
-				dstW = worldW
-				dstH = dstW / imgRadio
-			}
-		}
-
-		var cx, cy float32
-		cx = (worldW - dstW) / 2.0
-		cy = (worldH - dstH) / 2.0
+		cx, cy, dstW, dstH := p.backdropRect(imgW, imgH)
 		vs := []ebiten.Vertex{
 			{
 				DstX: cx, DstY: cy, SrcX: 0, SrcY: 0,
@@ -1146,24 +1304,114 @@ func (p *Game) drawBackground(dc drawContext) {
 	}
 }
 
-func (p *Game) onDraw(dc drawContext) {
+// backdropRect returns where a backdrop image of imgW x imgH is drawn in the
+// world image, in map modes other than repeat.
+func (p *Game) backdropRect(imgW, imgH float32) (cx, cy, dstW, dstH float32) {
+	worldW := float32(p.worldWidth_)
+	worldH := float32(p.worldHeight_)
+
+	imgRadio := (imgW / imgH)
+	worldRadio := (worldW / worldH)
+	// scale image's height to fit world's height
+	isScaleHeight := imgRadio > worldRadio
+	switch p.mapMode {
+	default:
+		dstW = worldW
+		dstH = worldH
+	case mapModeFillCut:
+		if isScaleHeight {
+			dstW = worldW
+			dstH = dstW / imgRadio
+		} else {
+			dstH = worldH
+			dstW = dstH * imgRadio
+		}
+	case mapModeFillRatio:
+		if isScaleHeight {
+			dstH = worldH
+			dstW = dstH * imgRadio
+		} else {
+			dstW = worldW
+			dstH = dstW / imgRadio
+		}
+	}
+	cx = (worldW - dstW) / 2.0
+	cy = (worldH - dstH) / 2.0
+	return
+}
+
+// backdropColorAt returns the color of the current backdrop at (x, y) in
+// stage coordinates.
+func (p *Game) backdropColorAt(x, y float64) color.Color {
+	c := p.costumes[p.costumeIndex_]
+	img, _, _ := c.needImage(p.fs)
+	origin := img.Origin()
+	imgW, imgH := float64(img.Bounds().Dx()), float64(img.Bounds().Dy())
+	wx, wy := float64(p.worldWidth_)/2+x, float64(p.worldHeight_)/2-y
+	var ix, iy float64
+	if p.mapMode == mapModeRepeat {
+		ix = math.Mod(wx-(float64(p.worldWidth_)-imgW)/2, imgW)
+		iy = math.Mod(wy-(float64(p.worldHeight_)-imgH)/2, imgH)
+		if ix < 0 {
+			ix += imgW
+		}
+		if iy < 0 {
+			iy += imgH
+		}
+	} else {
+		cx, cy, dstW, dstH := p.backdropRect(float32(imgW), float32(imgH))
+		ix = (wx - float64(cx)) * imgW / float64(dstW)
+		iy = (wy - float64(cy)) * imgH / float64(dstH)
+		if ix < 0 || iy < 0 || ix >= imgW || iy >= imgH {
+			return color.Transparent
+		}
+	}
+	pt := origin.Rect.Min
+	return origin.At(pt.X+int(ix), pt.Y+int(iy))
+}
+
+// onDraw draws the world, with the shapes shown by cam.
+func (p *Game) onDraw(dc drawContext, cam *Camera) {
 	dc.Fill(color.White)
 	p.drawBackground(dc)
-	p.getTurtle().draw(dc, p.fs)
+	p.drawMapLayers(dc)
+	p.getTurtle().draw(dc)
 
 	items := p.getItems()
 	for _, item := range items {
-		item.draw(dc)
+		if !isHUDShape(item) && cam.shows(item) {
+			item.draw(dc)
+		}
+	}
+}
+
+// onDrawHUD draws HUD shapes in screen space, after the world is rendered.
+func (p *Game) onDrawHUD(dc drawContext) {
+	items := p.getItems()
+	for _, item := range items {
+		if isHUDShape(item) {
+			item.draw(dc)
+		}
 	}
 }
 
+func isHUDShape(item Shape) bool {
+	o, ok := item.(hudShape)
+	return ok && o.isHUD()
+}
+
 func (p *Game) onHit(hc hitContext) (hr hitResult, ok bool) {
 	items := p.getItems()
-	i := len(items)
-	for i > 0 {
-		i--
-		if hr, ok = items[i].hit(hc); ok {
-			return
+	for _, hud := range [...]bool{true, false} { // HUD shapes are on top of the world
+		i := len(items)
+		for i > 0 {
+			i--
+			if isHUDShape(items[i]) != hud {
+				continue
+			}
+			if hr, ok = items[i].hit(hc); ok {
+				return
+			}
 		}
 	}
 	return hitResult{Target: p}, true
@@ -1267,7 +1515,7 @@ func (p *Game) getMousePos() (x, y float64) {
 
 func (p *Game) updateMousePos() {
 	x, y := p.input.mouseXY()
-	pos := p.Camera.screenToWorld(math32.NewVector2(float64(x), float64(y)))
+	_, pos := p.cameraAt(x, y)
 
 	worldW, worldH := p.worldSize_()
 	mx, my := int(pos.X)-(worldW>>1), (worldH>>1)-int(pos.Y)
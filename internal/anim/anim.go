@@ -54,6 +54,11 @@ type Anim struct {
 
 	currentFrame int
 	preFrame     int
+	progress     float64
+
+	// time played, in milliseconds, scaled by speedRatio
+	elapsed   float64
+	lastDelay float64
 
 	preRepeatCount int
 	//playing
@@ -113,6 +118,23 @@ func (a *Anim) SetLoop(isloop bool) *Anim {
 	return a
 }
 
+// SetSpeedRatio sets the speed of the animation, 1 by default. It takes effect
+// from the next Update, without jumping to another frame.
+func (a *Anim) SetSpeedRatio(ratio float64) *Anim {
+	a.speedRatio = ratio
+	return a
+}
+
+func (a *Anim) SpeedRatio() float64 {
+	return a.speedRatio
+}
+
+// Progress returns the progress of the animation, or of the current loop of a
+// looping animation, from 0 to 1.
+func (a *Anim) Progress() float64 {
+	return a.progress
+}
+
 func (a *Anim) Play() *Anim {
 	a.status = AnimstatusPlaying
 	return a
@@ -149,14 +171,18 @@ func (this *Anim) Update(delay float64) bool {
 	to := this.totalframe - 1
 	// Compute ratio
 	rangeval := float64(to + 1 - from)
-	ratio := delay * float64(this.fps*this.speedRatio) / 1000.0
+	this.elapsed += (delay - this.lastDelay) * this.speedRatio
+	this.lastDelay = delay
+	ratio := this.elapsed * this.fps / 1000.0
 	repeatCount := int(ratio/rangeval) >> 0
 	isReplay := repeatCount != this.preRepeatCount
 	if isReplay {
 		this.preRepeatCount = repeatCount
 	}
 	_, progress := math.Modf(ratio / rangeval)
+	this.progress = progress
 	if ratio >= rangeval && !this.isloop { // If we are out of range and not looping get back to caller
+		this.progress = 1
 		//add compete
 		this.interpolate(to)
 		if this.playingCallback != nil && this.preFrame != to {
@@ -214,6 +240,7 @@ func (this *Anim) onStop() {
 	if this.status == AnimstatusStop {
 		return
 	}
+	this.status = AnimstatusStop
 	if this.stopCallback != nil {
 		this.stopCallback()
 	}
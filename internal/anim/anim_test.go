@@ -0,0 +1,26 @@
+package anim
+
+import (
+	"testing"
+)
+
+func TestAnimStopAfterEnd(t *testing.T) {
+	a := NewAnim("test", 10, 5, false)
+	a.AddChannel("x", AnimValTypeFloat, []*AnimationKeyFrame{{Frame: 0, Value: 0.0}, {Frame: 4, Value: 4.0}})
+	stops := 0
+	a.SetOnStopingListener(func() {
+		stops++
+	})
+	for delay := 0.0; a.Update(delay); delay += 100 {
+	}
+	if stops != 1 || a.Status() != AnimstatusStop {
+		t.Fatal("ended:", stops, a.Status())
+	}
+	if v := a.SampleChannel("x"); v != 4.0 {
+		t.Fatal("last frame:", v)
+	}
+	a.Stop()
+	if stops != 1 {
+		t.Fatal("Stop after end:", stops)
+	}
+}
@@ -0,0 +1,252 @@
+// Package atlas loads sprite sheets exported by Aseprite (https://www.aseprite.org)
+// or TexturePacker (https://www.codeandweb.com/texturepacker) as JSON atlases,
+// in the hash or the array format.
+package atlas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Rect is a rectangle in pixels.
+type Rect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Frame is a frame of the sprite sheet.
+type Frame struct {
+	Name     string // file name without the extension
+	Filename string // file name in the atlas, like "run_01.png"
+
+	// Rect is the frame in the sheet image. If Rotated, the frame is stored
+	// turned 90 degrees clockwise, so it takes Rect.H x Rect.W pixels.
+	Rect    Rect
+	Rotated bool
+
+	// Source is the rectangle of the trimmed frame in the untrimmed image,
+	// which is SourceW x SourceH pixels.
+	Source           Rect
+	SourceW, SourceH int
+
+	// PivotX and PivotY are the pivot of the frame, relative to the size of
+	// the untrimmed image. The pivot is at the center by default.
+	PivotX, PivotY float64
+
+	Duration int // in milliseconds, 0 if not specified
+}
+
+// Tag is a named animation of frames.
+type Tag struct {
+	Name   string
+	Frames []int // indexes of frames, in playing order
+}
+
+// Atlas is a sprite sheet.
+type Atlas struct {
+	Image  string // path of the sheet image
+	Frames []*Frame
+	Tags   []*Tag
+}
+
+// Frame returns the index of the frame with the specified name, with or
+// without the extension, or -1 if not found.
+func (p *Atlas) Frame(name string) int {
+	for i, f := range p.Frames {
+		if f.Name == name || f.Filename == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// -------------------------------------------------------------------------------------
+
+type jsonSize struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type jsonPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type jsonFrame struct {
+	Filename         string     `json:"filename"`
+	Frame            Rect       `json:"frame"`
+	Rotated          bool       `json:"rotated"`
+	Trimmed          bool       `json:"trimmed"`
+	SpriteSourceSize *Rect      `json:"spriteSourceSize"`
+	SourceSize       *jsonSize  `json:"sourceSize"`
+	Pivot            *jsonPoint `json:"pivot"`
+	Duration         int        `json:"duration"`
+}
+
+type jsonTag struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"` // "forward", "reverse", "pingpong" or "pingpong_reverse"
+}
+
+type jsonMeta struct {
+	Image     string     `json:"image"`
+	FrameTags []*jsonTag `json:"frameTags"` // Aseprite
+}
+
+type jsonAtlas struct {
+	Frames     json.RawMessage     `json:"frames"`
+	Animations map[string][]string `json:"animations"` // TexturePacker, frame names by animation
+	Meta       jsonMeta            `json:"meta"`
+}
+
+// OpenFunc opens a file by its path relative to the root of a file system.
+type OpenFunc = func(name string) (io.ReadCloser, error)
+
+// Load loads the atlas file name. The image path of the atlas is relative to
+// the root of the file system.
+func Load(open OpenFunc, name string) (*Atlas, error) {
+	f, err := open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	a, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("atlas: %s: %w", name, err)
+	}
+	a.Image = path.Join(path.Dir(name), a.Image)
+	return a, nil
+}
+
+// Parse parses an atlas in JSON.
+func Parse(data []byte) (*Atlas, error) {
+	var ja jsonAtlas
+	if err := json.Unmarshal(data, &ja); err != nil {
+		return nil, err
+	}
+	frames, err := parseFrames(ja.Frames)
+	if err != nil {
+		return nil, err
+	}
+	a := &Atlas{Image: ja.Meta.Image, Frames: make([]*Frame, len(frames))}
+	for i, jf := range frames {
+		a.Frames[i] = newFrame(jf)
+	}
+	for _, jt := range ja.Meta.FrameTags {
+		if jt.From < 0 || jt.To >= len(frames) || jt.From > jt.To {
+			return nil, fmt.Errorf("tag %s: invalid frames %d..%d", jt.Name, jt.From, jt.To)
+		}
+		a.Tags = append(a.Tags, &Tag{Name: jt.Name, Frames: tagFrames(jt)})
+	}
+	names := make([]string, 0, len(ja.Animations))
+	for name := range ja.Animations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tag := &Tag{Name: name}
+		for _, fname := range ja.Animations[name] {
+			i := a.Frame(fname)
+			if i < 0 {
+				return nil, fmt.Errorf("animation %s: frame %s not found", name, fname)
+			}
+			tag.Frames = append(tag.Frames, i)
+		}
+		a.Tags = append(a.Tags, tag)
+	}
+	return a, nil
+}
+
+// parseFrames parses frames in the array format, or in the hash format whose
+// order of keys is the order of frames.
+func parseFrames(data json.RawMessage) (frames []*jsonFrame, err error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errors.New("no frames")
+	}
+	if data[0] == '[' {
+		err = json.Unmarshal(data, &frames)
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err = dec.Token(); err != nil { // {
+		return
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		f := new(jsonFrame)
+		if err = dec.Decode(f); err != nil {
+			return nil, err
+		}
+		f.Filename = tok.(string)
+		frames = append(frames, f)
+	}
+	return
+}
+
+func newFrame(jf *jsonFrame) *Frame {
+	f := &Frame{
+		Name: strings.TrimSuffix(jf.Filename, path.Ext(jf.Filename)), Filename: jf.Filename,
+		Rect: jf.Frame, Rotated: jf.Rotated,
+		Source:  Rect{W: jf.Frame.W, H: jf.Frame.H},
+		SourceW: jf.Frame.W, SourceH: jf.Frame.H,
+		PivotX: 0.5, PivotY: 0.5,
+		Duration: jf.Duration,
+	}
+	if jf.SpriteSourceSize != nil {
+		f.Source = *jf.SpriteSourceSize
+	}
+	if jf.SourceSize != nil {
+		f.SourceW, f.SourceH = jf.SourceSize.W, jf.SourceSize.H
+	}
+	if jf.Pivot != nil {
+		f.PivotX, f.PivotY = jf.Pivot.X, jf.Pivot.Y
+	}
+	return f
+}
+
+func tagFrames(jt *jsonTag) []int {
+	var frames []int
+	forward := func(from, to int) {
+		for i := from; i <= to; i++ {
+			frames = append(frames, i)
+		}
+	}
+	backward := func(from, to int) {
+		for i := from; i >= to; i-- {
+			frames = append(frames, i)
+		}
+	}
+	switch jt.Direction {
+	case "reverse":
+		backward(jt.To, jt.From)
+	case "pingpong":
+		forward(jt.From, jt.To)
+		backward(jt.To-1, jt.From+1)
+	case "pingpong_reverse":
+		backward(jt.To, jt.From)
+		forward(jt.From+1, jt.To-1)
+	default:
+		forward(jt.From, jt.To)
+	}
+	return frames
+}
+
+// -------------------------------------------------------------------------------------
@@ -0,0 +1,87 @@
+package atlas
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestLoadAsepriteHash(t *testing.T) {
+	const data = `{
+		"frames": {
+			"hero 1.aseprite": {"frame": {"x": 20, "y": 0, "w": 10, "h": 12}, "rotated": false, "trimmed": true,
+				"spriteSourceSize": {"x": 3, "y": 2, "w": 10, "h": 12}, "sourceSize": {"w": 16, "h": 16}, "duration": 80},
+			"hero 0.aseprite": {"frame": {"x": 0, "y": 0, "w": 16, "h": 16}, "rotated": false, "trimmed": false,
+				"spriteSourceSize": {"x": 0, "y": 0, "w": 16, "h": 16}, "sourceSize": {"w": 16, "h": 16}, "duration": 100},
+			"hero 2.aseprite": {"frame": {"x": 40, "y": 0, "w": 16, "h": 16}, "duration": 120}
+		},
+		"meta": {
+			"image": "hero.png",
+			"frameTags": [
+				{"name": "walk", "from": 0, "to": 2, "direction": "pingpong"},
+				{"name": "back", "from": 1, "to": 2, "direction": "reverse"}
+			]
+		}
+	}`
+	open := func(name string) (io.ReadCloser, error) {
+		if name != "sprites/hero/hero.json" {
+			return nil, os.ErrNotExist
+		}
+		return io.NopCloser(bytes.NewReader([]byte(data))), nil
+	}
+	a, err := Load(open, "sprites/hero/hero.json")
+	if err != nil {
+		t.Fatal("Load:", err)
+	}
+	if a.Image != "sprites/hero/hero.png" {
+		t.Fatal("Image:", a.Image)
+	}
+	if len(a.Frames) != 3 || a.Frame("hero 1") != 0 || a.Frame("hero 0") != 1 || a.Frame("hero 2") != 2 {
+		t.Fatal("frames are not in the order of the file:", a.Frames)
+	}
+	f := a.Frames[0]
+	if f.Rect != (Rect{20, 0, 10, 12}) || f.Source != (Rect{3, 2, 10, 12}) || f.SourceW != 16 || f.Duration != 80 {
+		t.Fatal("trimmed frame:", f)
+	}
+	if f := a.Frames[2]; f.Source != (Rect{0, 0, 16, 16}) || f.SourceW != 16 || f.PivotX != 0.5 {
+		t.Fatal("untrimmed frame:", f)
+	}
+	if len(a.Tags) != 2 {
+		t.Fatal("tags:", a.Tags)
+	}
+	if tag := a.Tags[0]; tag.Name != "walk" || !reflect.DeepEqual(tag.Frames, []int{0, 1, 2, 1}) {
+		t.Fatal("pingpong:", tag)
+	}
+	if tag := a.Tags[1]; !reflect.DeepEqual(tag.Frames, []int{2, 1}) {
+		t.Fatal("reverse:", tag)
+	}
+}
+
+func TestParseTexturePackerArray(t *testing.T) {
+	a, err := Parse([]byte(`{
+		"frames": [
+			{"filename": "run_01.png", "frame": {"x": 0, "y": 0, "w": 8, "h": 4}, "rotated": true, "trimmed": false,
+				"spriteSourceSize": {"x": 0, "y": 0, "w": 8, "h": 4}, "sourceSize": {"w": 8, "h": 4}, "pivot": {"x": 0.5, "y": 1}},
+			{"filename": "run_02.png", "frame": {"x": 4, "y": 0, "w": 8, "h": 4}}
+		],
+		"animations": {"run": ["run_02.png", "run_01.png"]},
+		"meta": {"image": "sheet.png"}
+	}`))
+	if err != nil {
+		t.Fatal("Parse:", err)
+	}
+	if f := a.Frames[0]; f.Name != "run_01" || f.Filename != "run_01.png" || !f.Rotated || f.PivotY != 1 {
+		t.Fatal("frame:", f)
+	}
+	if a.Frame("run_02") != 1 || a.Frame("run_02.png") != 1 || a.Frame("run_02.jpg") != -1 {
+		t.Fatal("Frame: names with and without the extension")
+	}
+	if len(a.Tags) != 1 || a.Tags[0].Name != "run" || !reflect.DeepEqual(a.Tags[0].Frames, []int{1, 0}) {
+		t.Fatal("animations:", a.Tags)
+	}
+	if _, err = Parse([]byte(`{"frames": [], "animations": {"run": ["none"]}}`)); err == nil {
+		t.Fatal("Parse: no error for unknown frames")
+	}
+}
@@ -2,6 +2,7 @@ package camera
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/goplus/spx/internal/math32"
 	"github.com/hajimehoshi/ebiten/v2"
@@ -13,6 +14,7 @@ type FreeCamera struct {
 	position    *math32.Vector2
 	zoom        *math32.Vector2
 	rotation    float64
+	offset      *math32.Vector2 // applied after clamping, e.g. for shaking
 	worldMatrix ebiten.GeoM
 }
 
@@ -35,6 +37,7 @@ func (cam *FreeCamera) init(viewPort *math32.Vector2, worldSize *math32.Vector2)
 	cam.position = math32.NewVector2(0, 0)
 	cam.zoom = math32.NewVector2(1, 1)
 	cam.rotation = 0
+	cam.offset = math32.NewVector2(0, 0)
 	cam.updateMatrix()
 	return
 }
@@ -49,16 +52,28 @@ func (c *FreeCamera) String() string {
 func (c *FreeCamera) SetViewPort(width, height float64) {
 	c.viewPort.X = width
 	c.viewPort.Y = height
+	c.updateMatrix()
 }
 
 func (c *FreeCamera) CameraCenter() *math32.Vector2 {
-	return c.worldSize.Scale(0.5)
+	return c.viewPort.Scale(0.5)
+}
+
+// ViewSize returns the size of the world area seen by the camera, that is the
+// bounding box of the viewport after zoom and rotation are applied.
+func (c *FreeCamera) ViewSize() *math32.Vector2 {
+	sin, cos := math.Sincos(c.rotation)
+	sin, cos = math.Abs(sin), math.Abs(cos)
+	w := (c.viewPort.X*cos + c.viewPort.Y*sin) / c.zoom.X
+	h := (c.viewPort.X*sin + c.viewPort.Y*cos) / c.zoom.Y
+	return math32.NewVector2(w, h)
 }
 
 func (c *FreeCamera) updateMatrix() {
 	c.worldMatrix.Reset()
 
-	limit := c.worldSize.Sub(c.viewPort).Scale(0.5)
+	limit := c.worldSize.Sub(c.ViewSize()).Scale(0.5)
+	limit.X, limit.Y = math.Max(limit.X, 0), math.Max(limit.Y, 0)
 	cx := c.position.X
 	cy := -c.position.Y
 	cx = math32.Clamp(cx, -limit.X, limit.X)
@@ -69,13 +84,15 @@ func (c *FreeCamera) updateMatrix() {
 	}
 	c.position.X = pos.X
 	c.position.Y = -pos.Y
+	pos.X += c.offset.X
+	pos.Y -= c.offset.Y
 
 	c.worldMatrix.Translate(c.worldSize.Sub(c.viewPort).Scale(0.5).Inverted().Coords())
 	c.worldMatrix.Translate(pos.Inverted().Coords())
 
 	c.worldMatrix.Translate(c.CameraCenter().Inverted().Coords())
 	c.worldMatrix.Scale(c.zoom.Coords())
-	c.worldMatrix.Rotate(c.rotation)
+	c.worldMatrix.Rotate(-c.rotation)
 	c.worldMatrix.Translate(c.CameraCenter().Coords())
 }
 
@@ -87,6 +104,16 @@ func (c *FreeCamera) Render(world, screen *ebiten.Image) error {
 	return nil
 }
 
+// RenderAt renders the world to screen, with the viewport placed at (x, y).
+func (c *FreeCamera) RenderAt(world, screen *ebiten.Image, x, y float64) error {
+	options := &ebiten.DrawImageOptions{
+		GeoM: c.worldMatrix,
+	}
+	options.GeoM.Translate(x, y)
+	screen.DrawImage(world, options)
+	return nil
+}
+
 func (c *FreeCamera) ScreenToWorld(point *math32.Vector2) *math32.Vector2 {
 	inverseMatrix := c.worldMatrix
 	inverseMatrix.Invert()
@@ -112,16 +139,43 @@ func (c *FreeCamera) MoveTo(x, y float64) {
 	c.updateMatrix()
 }
 
+// SetOffset sets an offset added to the camera position after it has been
+// clamped into the world.
+func (c *FreeCamera) SetOffset(x, y float64) {
+	c.offset.Set(x, y)
+	c.updateMatrix()
+}
+
 func (c *FreeCamera) Zoom(m float64) {
 	c.zoom = c.zoom.Scale(m)
 	c.updateMatrix()
 }
 
+func (c *FreeCamera) SetZoom(m float64) {
+	c.zoom.Set(m, m)
+	c.updateMatrix()
+}
+
+func (c *FreeCamera) GetZoom() float64 {
+	return c.zoom.X
+}
+
+// Rotate rotates the camera clockwise by theta (in radians), so the world
+// appears to rotate counterclockwise.
 func (c *FreeCamera) Rotate(theta float64) {
 	c.rotation += theta
 	c.updateMatrix()
 }
 
+func (c *FreeCamera) SetRotation(theta float64) {
+	c.rotation = theta
+	c.updateMatrix()
+}
+
+func (c *FreeCamera) GetRotation() float64 {
+	return c.rotation
+}
+
 func (c *FreeCamera) Reset() {
 	c.rotation = 0
 	c.zoom.Set(1, 1)
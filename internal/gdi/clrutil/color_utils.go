@@ -100,6 +100,34 @@ func MixRGB(r1, g1, b1 uint8, r2, g2, b2 uint8, fraction float64) (r, g, b uint8
 	return
 }
 
+// ShadeToSV returns the saturation and brightness (0-1) of a color of Scratch 2
+// with the shade (0-200): its hue is mixed with black below 50, and with white
+// above 50 up to 100. Shades from 100 to 200 go back to 0.
+//
+func ShadeToSV(shade float64) (s, v float64) {
+	shade = math.Mod(shade, 200)
+	if shade < 0 {
+		shade += 200
+	}
+	if shade > 100 {
+		shade = 200 - shade
+	}
+	if shade < 50 {
+		return 1, (10 + shade) / 60
+	}
+	return 1 - (shade-50)/60, 1
+}
+
+// SVToShade returns the shade (0-100) of Scratch 2 closest to saturation and
+// brightness (0-1), as the inverse of ShadeToSV.
+//
+func SVToShade(s, v float64) float64 {
+	if v < 1 {
+		return math.Max(0, math.Min(v*60-10, 50))
+	}
+	return math.Max(50, math.Min(50+(1-s)*60, 100))
+}
+
 // Random returns a random color.
 //
 func Random() (uint8, uint8, uint8) {
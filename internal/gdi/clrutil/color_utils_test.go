@@ -0,0 +1,29 @@
+package clrutil
+
+import (
+	"math"
+	"testing"
+)
+
+func TestShadeToSV(t *testing.T) {
+	for shade := 0.0; shade <= 100; shade += 5 {
+		// the color model of Scratch 2, from red
+		r, g, b := HSV2RGB(0, 1, 1)
+		if shade < 50 {
+			r, g, b = MixRGB(0, 0, 0, r, g, b, (10+shade)/60)
+		} else {
+			r, g, b = MixRGB(r, g, b, 255, 255, 255, (shade-50)/60)
+		}
+		_, ws, wv := RGB2HSV(r, g, b)
+		s, v := ShadeToSV(shade)
+		if math.Abs(s-ws) > 0.01 || math.Abs(v-wv) > 0.01 {
+			t.Fatal("ShadeToSV:", shade, s, v, "expected:", ws, wv)
+		}
+		if s2, v2 := ShadeToSV(200 - shade); s2 != s || v2 != v {
+			t.Fatal("ShadeToSV:", 200-shade, s2, v2)
+		}
+		if back := SVToShade(s, v); math.Abs(back-shade) > 1e-9 {
+			t.Fatal("SVToShade:", s, v, back, "expected:", shade)
+		}
+	}
+}
@@ -99,45 +99,34 @@ func ToRGB(H uint16, S, V uint8) (R uint8, G uint8, B uint8) {
 	return
 }
 
-/*
-// ToRGBf converts HSV values into RGB.
+// ToRGBf converts HSV values into RGB ones, all from 0 to 1. Hue wraps
+// around.
 //
 func ToRGBf(hue, saturation, value float64) (red, green, blue float64) {
-	i := math.Min(5, math.Floor(hue*6))
+	hue -= math.Floor(hue)
+	i := math.Floor(hue * 6)
 	f := hue*6 - i
 	p := value * (1 - saturation)
 	q := value * (1 - f*saturation)
 	t := value * (1 - (1-f)*saturation)
 	switch i {
 	case 0:
-		red = value
-		green = t
-		blue = p
+		red, green, blue = value, t, p
 	case 1:
-		red = q
-		green = value
-		blue = p
+		red, green, blue = q, value, p
 	case 2:
-		red = p
-		green = value
-		blue = t
+		red, green, blue = p, value, t
 	case 3:
-		red = p
-		green = q
-		blue = value
+		red, green, blue = p, q, value
 	case 4:
-		red = t
-		green = p
-		blue = value
-	case 5:
-		red = value
-		green = p
-		blue = q
+		red, green, blue = t, p, value
+	default:
+		red, green, blue = value, p, q
 	}
 	return
 }
 
-// FromRGBf converts RGB values into HSV.
+// FromRGBf converts RGB values into HSV ones, all from 0 to 1.
 //
 func FromRGBf(red, green, blue float64) (hue, saturation, value float64) {
 	max := max3f(red, green, blue)
@@ -148,23 +137,21 @@ func FromRGBf(red, green, blue float64) (hue, saturation, value float64) {
 	}
 	value = max
 	if delta == 0 {
-		hue = 0
-	} else {
-		switch max {
-		case red:
-			hue = (green - blue) / delta / 6
-			if green < blue {
-				hue++
-			}
-		case green:
-			hue = (blue-red)/delta/6 + 1.0/3
-		case blue:
-			hue = (red-green)/delta/6 + 2.0/3
+		return
+	}
+	switch max {
+	case red:
+		hue = (green - blue) / delta / 6
+		if green < blue {
+			hue++
 		}
+	case green:
+		hue = (blue-red)/delta/6 + 1.0/3
+	default:
+		hue = (red-green)/delta/6 + 2.0/3
 	}
 	return
 }
-*/
 
 func min3f(a, b, c float64) float64 {
 	if b < a {
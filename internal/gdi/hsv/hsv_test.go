@@ -0,0 +1,34 @@
+package hsv
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRGBfRoundTrip(t *testing.T) {
+	for r := 0; r < 256; r += 15 {
+		for g := 0; g < 256; g += 17 {
+			for b := 0; b < 256; b += 5 {
+				h, s, v := FromRGBf(float64(r)/255, float64(g)/255, float64(b)/255)
+				if h < 0 || h >= 1 || s < 0 || s > 1 || v < 0 || v > 1 {
+					t.Fatal("FromRGBf:", r, g, b, h, s, v)
+				}
+				r2, g2, b2 := ToRGBf(h, s, v)
+				if math.Round(r2*255) != float64(r) || math.Round(g2*255) != float64(g) || math.Round(b2*255) != float64(b) {
+					t.Fatal("round trip:", r, g, b, r2*255, g2*255, b2*255)
+				}
+			}
+		}
+	}
+}
+
+func TestToRGBf(t *testing.T) {
+	for _, hue := range []float64{0, 1, -1, 2} { // wraps around to red
+		if r, g, b := ToRGBf(hue, 1, 1); r != 1 || g != 0 || b != 0 {
+			t.Fatal("red:", hue, r, g, b)
+		}
+	}
+	if r, g, b := ToRGBf(2.0/3, 1, 0.5); r != 0 || g != 0 || b != 0.5 {
+		t.Fatal("blue:", r, g, b)
+	}
+}
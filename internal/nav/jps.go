@@ -0,0 +1,116 @@
+package nav
+
+// Jump point search on grids without corner cutting: a diagonal move requires
+// both cells beside it to be walkable.
+
+// jumpSuccessors calls fn with the jump points reachable from n, in the
+// directions not pruned by the direction from its parent.
+func (s *search) jumpSuccessors(n *node, fn func(pt Point)) {
+	x, y := n.pt.X, n.pt.Y
+	if n.parent == nil {
+		for _, d := range directions {
+			if s.canMove(x, y, d.X, d.Y) {
+				if pt, ok := s.jump(x+d.X, y+d.Y, d.X, d.Y); ok {
+					fn(pt)
+				}
+			}
+		}
+		return
+	}
+	dx, dy := sign(x-n.parent.pt.X), sign(y-n.parent.pt.Y)
+	s.prunedNeighbors(x, y, dx, dy, func(ndx, ndy int) {
+		if pt, ok := s.jump(x+ndx, y+ndy, ndx, ndy); ok {
+			fn(pt)
+		}
+	})
+}
+
+// prunedNeighbors calls fn with the directions to the neighbors of (x, y)
+// worth exploring, when it was reached in the direction (dx, dy).
+func (s *search) prunedNeighbors(x, y, dx, dy int, fn func(dx, dy int)) {
+	g := s.grid
+	switch {
+	case dx != 0 && dy != 0:
+		nextX, nextY := g.Walkable(x+dx, y), g.Walkable(x, y+dy)
+		if nextY {
+			fn(0, dy)
+		}
+		if nextX {
+			fn(dx, 0)
+		}
+		if nextX && nextY && g.Walkable(x+dx, y+dy) {
+			fn(dx, dy)
+		}
+	case dx != 0:
+		next, up, down := g.Walkable(x+dx, y), g.Walkable(x, y+1), g.Walkable(x, y-1)
+		if next {
+			fn(dx, 0)
+			if up && g.Walkable(x+dx, y+1) {
+				fn(dx, 1)
+			}
+			if down && g.Walkable(x+dx, y-1) {
+				fn(dx, -1)
+			}
+		}
+		if up {
+			fn(0, 1)
+		}
+		if down {
+			fn(0, -1)
+		}
+	default:
+		next, right, left := g.Walkable(x, y+dy), g.Walkable(x+1, y), g.Walkable(x-1, y)
+		if next {
+			fn(0, dy)
+			if right && g.Walkable(x+1, y+dy) {
+				fn(1, dy)
+			}
+			if left && g.Walkable(x-1, y+dy) {
+				fn(-1, dy)
+			}
+		}
+		if right {
+			fn(1, 0)
+		}
+		if left {
+			fn(-1, 0)
+		}
+	}
+}
+
+// jump moves from (x, y) in the direction (dx, dy), and returns the first
+// jump point: the goal, or a cell with a forced neighbor.
+func (s *search) jump(x, y, dx, dy int) (Point, bool) {
+	g := s.grid
+	for {
+		if !g.Walkable(x, y) {
+			return Point{}, false
+		}
+		if x == s.goal.X && y == s.goal.Y {
+			return Point{x, y}, true
+		}
+		switch {
+		case dx != 0 && dy != 0:
+			if _, ok := s.jump(x+dx, y, dx, 0); ok {
+				return Point{x, y}, true
+			}
+			if _, ok := s.jump(x, y+dy, 0, dy); ok {
+				return Point{x, y}, true
+			}
+		case dx != 0:
+			if g.Walkable(x, y+1) && !g.Walkable(x-dx, y+1) ||
+				g.Walkable(x, y-1) && !g.Walkable(x-dx, y-1) {
+				return Point{x, y}, true
+			}
+		default:
+			if g.Walkable(x+1, y) && !g.Walkable(x+1, y-dy) ||
+				g.Walkable(x-1, y) && !g.Walkable(x-1, y-dy) {
+				return Point{x, y}, true
+			}
+		}
+		if !s.canMove(x, y, dx, dy) {
+			return Point{}, false
+		}
+		x, y = x+dx, y+dy
+	}
+}
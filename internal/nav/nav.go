@@ -0,0 +1,205 @@
+// Package nav finds paths on a grid of walkable cells, by A* or by jump point
+// search.
+package nav
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Point is a cell of a grid, by its column and row.
+type Point struct {
+	X, Y int
+}
+
+// Grid is a grid of walkable and blocked cells.
+type Grid struct {
+	w, h     int
+	walkable []bool
+}
+
+// NewGrid creates a w x h grid, walkable(x, y) reports if the cell at column
+// x and row y is walkable.
+func NewGrid(w, h int, walkable func(x, y int) bool) *Grid {
+	g := &Grid{w: w, h: h, walkable: make([]bool, w*h)}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			g.walkable[y*w+x] = walkable(x, y)
+		}
+	}
+	return g
+}
+
+func (p *Grid) Size() (w, h int) {
+	return p.w, p.h
+}
+
+// Walkable reports if the cell (x, y) is walkable. Cells outside of the grid
+// are blocked.
+func (p *Grid) Walkable(x, y int) bool {
+	return x >= 0 && y >= 0 && x < p.w && y < p.h && p.walkable[y*p.w+x]
+}
+
+func (p *Grid) SetWalkable(x, y int, walkable bool) {
+	if x >= 0 && y >= 0 && x < p.w && y < p.h {
+		p.walkable[y*p.w+x] = walkable
+	}
+}
+
+// Options are the options of FindPath.
+type Options struct {
+	Diagonal bool // allow diagonal moves, but not between two blocked cells
+	JPS      bool // use jump point search, requires Diagonal
+}
+
+// FindPath returns the shortest path from from to to, including both, or
+// nil if there is no path.
+func (p *Grid) FindPath(from, to Point, opts Options) []Point {
+	if !p.Walkable(from.X, from.Y) || !p.Walkable(to.X, to.Y) {
+		return nil
+	}
+	if from == to {
+		return []Point{from}
+	}
+	s := &search{grid: p, goal: to, diagonal: opts.Diagonal, nodes: make(map[Point]*node)}
+	if opts.JPS && opts.Diagonal {
+		s.successors = s.jumpSuccessors
+	} else {
+		s.successors = s.neighbors
+	}
+	return s.run(from)
+}
+
+// -------------------------------------------------------------------------------------
+
+type node struct {
+	pt     Point
+	parent *node
+	g, f   float64
+	index  int // in the open list, -1 if closed
+}
+
+type openList []*node
+
+func (p openList) Len() int           { return len(p) }
+func (p openList) Less(i, j int) bool { return p[i].f < p[j].f }
+func (p openList) Swap(i, j int) {
+	p[i], p[j] = p[j], p[i]
+	p[i].index, p[j].index = i, j
+}
+
+func (p *openList) Push(x interface{}) {
+	n := x.(*node)
+	n.index = len(*p)
+	*p = append(*p, n)
+}
+
+func (p *openList) Pop() interface{} {
+	old := *p
+	n := old[len(old)-1]
+	n.index = -1
+	*p = old[:len(old)-1]
+	return n
+}
+
+type search struct {
+	grid       *Grid
+	goal       Point
+	diagonal   bool
+	nodes      map[Point]*node
+	open       openList
+	successors func(n *node, fn func(pt Point))
+}
+
+func (s *search) run(from Point) []Point {
+	start := &node{pt: from, f: s.heuristic(from)}
+	s.nodes[from] = start
+	heap.Push(&s.open, start)
+	for s.open.Len() > 0 {
+		n := heap.Pop(&s.open).(*node)
+		if n.pt == s.goal {
+			return s.path(n)
+		}
+		s.successors(n, func(pt Point) {
+			g := n.g + s.distance(n.pt, pt)
+			next, ok := s.nodes[pt]
+			if !ok {
+				next = &node{pt: pt, index: -1}
+				s.nodes[pt] = next
+			} else if g >= next.g {
+				return
+			}
+			next.parent, next.g, next.f = n, g, g+s.heuristic(pt)
+			if next.index < 0 {
+				heap.Push(&s.open, next)
+			} else {
+				heap.Fix(&s.open, next.index)
+			}
+		})
+	}
+	return nil
+}
+
+// path returns the cells from the start to n, filling the cells between jump
+// points.
+func (s *search) path(n *node) []Point {
+	var ret []Point
+	for ; n.parent != nil; n = n.parent {
+		dx, dy := sign(n.parent.pt.X-n.pt.X), sign(n.parent.pt.Y-n.pt.Y)
+		for pt := n.pt; pt != n.parent.pt; pt.X, pt.Y = pt.X+dx, pt.Y+dy {
+			ret = append(ret, pt)
+		}
+	}
+	ret = append(ret, n.pt)
+	for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+	return ret
+}
+
+func (s *search) distance(a, b Point) float64 {
+	dx, dy := math.Abs(float64(a.X-b.X)), math.Abs(float64(a.Y-b.Y))
+	if s.diagonal { // octile distance
+		return math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)
+	}
+	return dx + dy
+}
+
+func (s *search) heuristic(pt Point) float64 {
+	return s.distance(pt, s.goal)
+}
+
+// canMove reports if it can move from (x, y) to the neighbor cell in the
+// direction (dx, dy). Diagonal moves can't cut corners.
+func (s *search) canMove(x, y, dx, dy int) bool {
+	g := s.grid
+	if !g.Walkable(x+dx, y+dy) {
+		return false
+	}
+	if dx != 0 && dy != 0 {
+		return s.diagonal && g.Walkable(x+dx, y) && g.Walkable(x, y+dy)
+	}
+	return true
+}
+
+var directions = [...]Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+func (s *search) neighbors(n *node, fn func(pt Point)) {
+	x, y := n.pt.X, n.pt.Y
+	for _, d := range directions {
+		if s.canMove(x, y, d.X, d.Y) {
+			fn(Point{x + d.X, y + d.Y})
+		}
+	}
+}
+
+func sign(v int) int {
+	if v > 0 {
+		return 1
+	} else if v < 0 {
+		return -1
+	}
+	return 0
+}
+
+// -------------------------------------------------------------------------------------
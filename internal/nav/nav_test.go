@@ -0,0 +1,97 @@
+package nav
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func gridOf(rows ...string) *Grid {
+	return NewGrid(len(rows[0]), len(rows), func(x, y int) bool {
+		return rows[y][x] != '#'
+	})
+}
+
+// pathLen returns the length of path, or +Inf if it isn't a valid path on g.
+func pathLen(g *Grid, path []Point) float64 {
+	l := 0.0
+	for i, pt := range path {
+		if !g.Walkable(pt.X, pt.Y) {
+			return math.Inf(1)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := path[i-1]
+		dx, dy := pt.X-prev.X, pt.Y-prev.Y
+		if dx < -1 || dx > 1 || dy < -1 || dy > 1 {
+			return math.Inf(1) // not adjacent
+		}
+		if dx != 0 && dy != 0 && (!g.Walkable(prev.X+dx, prev.Y) || !g.Walkable(prev.X, prev.Y+dy)) {
+			return math.Inf(1) // cutting a corner
+		}
+		l += math.Hypot(float64(dx), float64(dy))
+	}
+	return l
+}
+
+func TestFindPath(t *testing.T) {
+	g := gridOf(
+		".....",
+		".###.",
+		"...#.",
+		"##.#.",
+		"..#..",
+	)
+	from, to := Point{0, 0}, Point{3, 4}
+	path := g.FindPath(from, to, Options{})
+	if len(path) != 10 || path[0] != from || path[len(path)-1] != to || pathLen(g, path) != 9 {
+		t.Fatal("FindPath:", path)
+	}
+	if path := g.FindPath(from, Point{0, 4}, Options{}); path != nil {
+		t.Fatal("FindPath: unreachable cell", path)
+	}
+	if path := g.FindPath(from, from, Options{}); len(path) != 1 {
+		t.Fatal("FindPath: from == to", path)
+	}
+}
+
+func TestNoCornerCutting(t *testing.T) {
+	g := gridOf(
+		".#",
+		"..",
+	)
+	for _, jps := range []bool{false, true} {
+		path := g.FindPath(Point{0, 0}, Point{1, 1}, Options{Diagonal: true, JPS: jps})
+		if len(path) != 3 || math.IsInf(pathLen(g, path), 1) {
+			t.Fatal("FindPath: cut a corner", path, jps)
+		}
+	}
+}
+
+func TestJPS(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		g := NewGrid(20, 15, func(x, y int) bool {
+			return rnd.Intn(4) != 0
+		})
+		from := Point{rnd.Intn(20), rnd.Intn(15)}
+		to := Point{rnd.Intn(20), rnd.Intn(15)}
+		g.SetWalkable(from.X, from.Y, true)
+		g.SetWalkable(to.X, to.Y, true)
+		astar := g.FindPath(from, to, Options{Diagonal: true})
+		jps := g.FindPath(from, to, Options{Diagonal: true, JPS: true})
+		if (astar == nil) != (jps == nil) {
+			t.Fatal("JPS: reachability differs", astar, jps)
+		}
+		if astar == nil {
+			continue
+		}
+		if l1, l2 := pathLen(g, astar), pathLen(g, jps); math.Abs(l1-l2) > 1e-9 {
+			t.Fatal("JPS: path length differs", l1, l2, astar, jps)
+		}
+		if jps[0] != from || jps[len(jps)-1] != to {
+			t.Fatal("JPS: wrong ends", jps)
+		}
+	}
+}
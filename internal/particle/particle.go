@@ -0,0 +1,179 @@
+// Package particle simulates emitters of particles: points emitted at a rate
+// or in bursts, which move under gravity and die at the end of their
+// lifetimes. Their size, alpha and color change over their lives by curves.
+package particle
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+)
+
+// Curve is a value over the life of a particle: values evenly spaced from its
+// birth to its death, linearly interpolated. An empty curve is 1.
+type Curve []float64
+
+// At returns the value at life t, from 0 to 1.
+func (c Curve) At(t float64) float64 {
+	switch len(c) {
+	case 0:
+		return 1
+	case 1:
+		return c[0]
+	}
+	i, f := segment(len(c), t)
+	return c[i] + (c[i+1]-c[i])*f
+}
+
+// Gradient is a color over the life of a particle, like Curve. An empty
+// gradient is white.
+type Gradient []color.RGBA
+
+// At returns the color at life t, from 0 to 1, each component from 0 to 1.
+func (g Gradient) At(t float64) (r, gr, b, a float64) {
+	switch len(g) {
+	case 0:
+		return 1, 1, 1, 1
+	case 1:
+		c := g[0]
+		return float64(c.R) / 255, float64(c.G) / 255, float64(c.B) / 255, float64(c.A) / 255
+	}
+	i, f := segment(len(g), t)
+	c0, c1 := g[i], g[i+1]
+	lerp := func(v0, v1 uint8) float64 {
+		return (float64(v0) + (float64(v1)-float64(v0))*f) / 255
+	}
+	return lerp(c0.R, c1.R), lerp(c0.G, c1.G), lerp(c0.B, c1.B), lerp(c0.A, c1.A)
+}
+
+// segment returns the segment i of n evenly spaced values where life t is,
+// and the fraction of t in it.
+func segment(n int, t float64) (i int, f float64) {
+	t = math.Max(0, math.Min(t, 1)) * float64(n-1)
+	i = int(t)
+	if i >= n-1 {
+		return n - 2, 1
+	}
+	return i, t - float64(i)
+}
+
+// -------------------------------------------------------------------------------------
+
+// Config is the configuration of an emitter.
+type Config struct {
+	Rate     float64 // particles emitted per second
+	Burst    int     // particles emitted when the emitter starts
+	Duration float64 // seconds emitting at Rate, 0 for ever
+	Max      int     // max live particles, 0 for no limit
+
+	Lifetime, LifetimeVariance float64 // in seconds
+	Speed, SpeedVariance       float64 // in pixels per second
+
+	// Direction is the heading of particles in degrees, 0 is up and 90 is
+	// right. They are emitted in Direction ± Spread.
+	Direction, Spread float64
+
+	GravityX, GravityY float64 // in pixels per second squared
+
+	Size  Curve
+	Alpha Curve
+	Color Gradient
+}
+
+// Particle is a live particle. Once emitted, it doesn't follow the emitter when
+// the emitter moves.
+type Particle struct {
+	X, Y, VX, VY  float64
+	Age, Lifetime float64 // in seconds
+}
+
+// Life returns the age of the particle relative to its lifetime, from 0 to 1.
+func (p *Particle) Life() float64 {
+	return p.Age / p.Lifetime
+}
+
+// Emitter is an emitter of particles, at (X, Y).
+type Emitter struct {
+	Config
+	X, Y      float64
+	Particles []Particle
+
+	emitting bool
+	elapsed  float64 // seconds since the emitter started
+	pending  float64 // fraction of a particle to emit
+	rand     *rand.Rand
+}
+
+// New creates a stopped emitter, using seed for random numbers.
+func New(conf Config, seed int64) *Emitter {
+	return &Emitter{Config: conf, rand: rand.New(rand.NewSource(seed))}
+}
+
+// Start emits a burst of particles and starts emitting at Rate.
+func (p *Emitter) Start() {
+	p.emitting, p.elapsed, p.pending = true, 0, 0
+	p.Emit(p.Burst)
+}
+
+// Stop stops emitting. Live particles live on.
+func (p *Emitter) Stop() {
+	p.emitting = false
+}
+
+// Emitting reports if the emitter emits at Rate.
+func (p *Emitter) Emitting() bool {
+	return p.emitting
+}
+
+// Emit emits n particles at once, within Max.
+func (p *Emitter) Emit(n int) {
+	for i := 0; i < n; i++ {
+		if p.Max > 0 && len(p.Particles) >= p.Max {
+			return
+		}
+		lifetime := p.Lifetime + p.LifetimeVariance*p.random()
+		if lifetime <= 0 {
+			continue
+		}
+		speed := p.Speed + p.SpeedVariance*p.random()
+		sin, cos := math.Sincos((p.Direction + p.Spread*p.random()) * math.Pi / 180)
+		p.Particles = append(p.Particles, Particle{
+			X: p.X, Y: p.Y, VX: speed * sin, VY: speed * cos, Lifetime: lifetime,
+		})
+	}
+}
+
+// random returns a random number in [-1, 1).
+func (p *Emitter) random() float64 {
+	return p.rand.Float64()*2 - 1
+}
+
+// Update advances the emitter and its particles by dt seconds.
+func (p *Emitter) Update(dt float64) {
+	live := p.Particles[:0]
+	for _, pt := range p.Particles {
+		if pt.Age += dt; pt.Age >= pt.Lifetime {
+			continue
+		}
+		pt.X += (pt.VX + p.GravityX*dt/2) * dt
+		pt.Y += (pt.VY + p.GravityY*dt/2) * dt
+		pt.VX += p.GravityX * dt
+		pt.VY += p.GravityY * dt
+		live = append(live, pt)
+	}
+	p.Particles = live
+
+	if !p.emitting {
+		return
+	}
+	emitDt := dt
+	if p.Duration > 0 && p.elapsed+dt >= p.Duration {
+		emitDt = math.Max(0, p.Duration-p.elapsed)
+		p.emitting = false
+	}
+	p.elapsed += dt
+	p.pending += p.Rate * emitDt
+	n := int(p.pending)
+	p.pending -= float64(n)
+	p.Emit(n)
+}
@@ -0,0 +1,71 @@
+package particle
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCurve(t *testing.T) {
+	c := Curve{1, 0.5, 0}
+	for _, tc := range []struct{ t, want float64 }{{0, 1}, {0.25, 0.75}, {0.5, 0.5}, {1, 0}, {2, 0}, {-1, 1}} {
+		if v := c.At(tc.t); !near(v, tc.want) {
+			t.Fatal("Curve.At:", tc.t, v)
+		}
+	}
+	if v := (Curve{}).At(0.5); v != 1 {
+		t.Fatal("empty curve:", v)
+	}
+	g := Gradient{{R: 255, A: 255}, {B: 255, A: 0}}
+	if r, gr, b, a := g.At(0.5); !near(r, 0.5) || gr != 0 || !near(b, 0.5) || !near(a, 0.5) {
+		t.Fatal("Gradient.At:", r, gr, b, a)
+	}
+	if r, gr, b, a := (Gradient{color.RGBA{R: 255, A: 255}}).At(0.3); r != 1 || gr != 0 || b != 0 || a != 1 {
+		t.Fatal("one color:", r, gr, b, a)
+	}
+}
+
+func TestEmitter(t *testing.T) {
+	em := New(Config{Rate: 10, Burst: 5, Duration: 1, Lifetime: 2, Speed: 10, GravityY: -10}, 1)
+	em.X, em.Y = 100, 0
+	em.Start()
+	if len(em.Particles) != 5 {
+		t.Fatal("burst:", len(em.Particles))
+	}
+	pt := em.Particles[0]
+	if pt.X != 100 || pt.VX != 0 || pt.VY != 10 {
+		t.Fatal("particle:", pt)
+	}
+	for i := 0; i < 8; i++ {
+		em.Update(0.125)
+	}
+	if len(em.Particles) != 15 || em.Emitting() {
+		t.Fatal("after 1s:", len(em.Particles), em.Emitting())
+	}
+	// v = 10 - 10t, y = 10t - 5t²
+	if pt := em.Particles[0]; !near(pt.VY, 0) || !near(pt.Y, 5) || !near(pt.Life(), 0.5) {
+		t.Fatal("moved:", pt)
+	}
+	em.Update(1.05) // the burst dies
+	if len(em.Particles) != 10 {
+		t.Fatal("after 2.05s:", len(em.Particles))
+	}
+}
+
+func TestEmitterMax(t *testing.T) {
+	em := New(Config{Rate: 1000, Max: 20, Lifetime: 1, Spread: 180}, 1)
+	em.Start()
+	em.Update(0.5)
+	if len(em.Particles) != 20 || !em.Emitting() {
+		t.Fatal("max:", len(em.Particles))
+	}
+	em.Stop()
+	em.Update(1)
+	if len(em.Particles) != 0 {
+		t.Fatal("stopped:", len(em.Particles))
+	}
+}
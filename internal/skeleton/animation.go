@@ -0,0 +1,319 @@
+package skeleton
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+type timelineKind int
+
+const (
+	rotateTimeline timelineKind = iota
+	translateTimeline
+	scaleTimeline
+	shearTimeline
+	colorTimeline
+)
+
+// curve is the interpolation from a key to the next one.
+type curve struct {
+	stepped bool
+	bezier  [][4]float64 // cx1, cy1, cx2, cy2 of each value, normalized to [0, 1]; nil for linear
+}
+
+type key struct {
+	time   float64
+	values [4]float64
+	curve  curve
+}
+
+type timeline struct {
+	kind  timelineKind
+	index int // of the bone, or of the slot of a color timeline
+	keys  []*key
+}
+
+type attachmentKey struct {
+	time float64
+	name string // "" hides the attachment
+}
+
+type attachmentTimeline struct {
+	slot int
+	keys []attachmentKey
+}
+
+// Animation is a named animation of a skeleton.
+type Animation struct {
+	Name     string
+	Duration float64 // in seconds
+
+	timelines   []*timeline
+	attachments []*attachmentTimeline
+	wrapAngles  bool // interpolate rotations the shortest way, as Spine 3.x
+}
+
+// -------------------------------------------------------------------------------------
+
+type jsonKey struct {
+	Time  float64         `json:"time"`
+	Angle *float64        `json:"angle"` // rotation of Spine 3.x
+	Value *float64        `json:"value"` // rotation of Spine 4.x
+	X     *float64        `json:"x"`
+	Y     *float64        `json:"y"`
+	Color string          `json:"color"` // of Spine 3.x
+	RGBA  string          `json:"rgba"`  // of Spine 4.x
+	Name  *string         `json:"name"`
+	Curve json.RawMessage `json:"curve"`
+	C2    float64         `json:"c2"`
+	C3    float64         `json:"c3"`
+	C4    float64         `json:"c4"`
+}
+
+type jsonAnimation struct {
+	Bones map[string]map[string][]*jsonKey `json:"bones"`
+	Slots map[string]map[string][]*jsonKey `json:"slots"`
+}
+
+func newAnimation(d *Data, name string, ja *jsonAnimation, spine4 bool) (*Animation, error) {
+	a := &Animation{Name: name, wrapAngles: !spine4}
+	for boneName, tls := range ja.Bones {
+		bone := d.boneIndex(boneName)
+		if bone < 0 {
+			return nil, fmt.Errorf("bone %s not found", boneName)
+		}
+		for kind, jkeys := range tls {
+			tl := &timeline{index: bone}
+			switch kind {
+			case "rotate":
+				tl.kind = rotateTimeline
+			case "translate":
+				tl.kind = translateTimeline
+			case "scale":
+				tl.kind = scaleTimeline
+			case "shear":
+				tl.kind = shearTimeline
+			default:
+				continue
+			}
+			if err := a.addTimeline(tl, jkeys, spine4); err != nil {
+				return nil, fmt.Errorf("bone %s: %s: %w", boneName, kind, err)
+			}
+		}
+	}
+	for slotName, tls := range ja.Slots {
+		slot := d.slotIndex(slotName)
+		if slot < 0 {
+			return nil, fmt.Errorf("slot %s not found", slotName)
+		}
+		for kind, jkeys := range tls {
+			switch kind {
+			case "attachment":
+				at := &attachmentTimeline{slot: slot}
+				for _, jk := range jkeys {
+					k := attachmentKey{time: jk.Time}
+					if jk.Name != nil {
+						k.name = *jk.Name
+					}
+					at.keys = append(at.keys, k)
+				}
+				if n := len(at.keys); n > 0 {
+					a.Duration = math.Max(a.Duration, at.keys[n-1].time)
+					a.attachments = append(a.attachments, at)
+				}
+			case "color", "rgba":
+				tl := &timeline{kind: colorTimeline, index: slot}
+				if err := a.addTimeline(tl, jkeys, spine4); err != nil {
+					return nil, fmt.Errorf("slot %s: %s: %w", slotName, kind, err)
+				}
+			}
+		}
+	}
+	// apply timelines in a stable order
+	sort.SliceStable(a.timelines, func(i, j int) bool {
+		ti, tj := a.timelines[i], a.timelines[j]
+		return ti.index < tj.index || ti.index == tj.index && ti.kind < tj.kind
+	})
+	sort.SliceStable(a.attachments, func(i, j int) bool {
+		return a.attachments[i].slot < a.attachments[j].slot
+	})
+	return a, nil
+}
+
+func (p *timeline) channels() int {
+	switch p.kind {
+	case rotateTimeline:
+		return 1
+	case colorTimeline:
+		return 4
+	}
+	return 2
+}
+
+func (a *Animation) addTimeline(tl *timeline, jkeys []*jsonKey, spine4 bool) error {
+	n := tl.channels()
+	for _, jk := range jkeys {
+		k := &key{time: jk.Time}
+		switch tl.kind {
+		case rotateTimeline:
+			if jk.Value != nil {
+				k.values[0] = *jk.Value
+			} else {
+				k.values[0] = valueOr(jk.Angle, 0)
+			}
+		case scaleTimeline:
+			k.values[0], k.values[1] = valueOr(jk.X, 1), valueOr(jk.Y, 1)
+		case colorTimeline:
+			s := jk.RGBA
+			if s == "" {
+				s = jk.Color
+			}
+			c, err := parseColor(s)
+			if err != nil {
+				return err
+			}
+			k.values = [4]float64{c.R, c.G, c.B, c.A}
+		default:
+			k.values[0], k.values[1] = valueOr(jk.X, 0), valueOr(jk.Y, 0)
+		}
+		c, err := parseCurve(jk, n, spine4)
+		if err != nil {
+			return err
+		}
+		k.curve = c
+		tl.keys = append(tl.keys, k)
+	}
+	if len(tl.keys) == 0 {
+		return nil
+	}
+	if spine4 {
+		// normalize control points in time and value to [0, 1]
+		for i, k0 := range tl.keys[:len(tl.keys)-1] {
+			k1 := tl.keys[i+1]
+			for c, bz := range k0.curve.bezier {
+				dt, dv := k1.time-k0.time, k1.values[c]-k0.values[c]
+				norm := [4]float64{1.0 / 3, 1.0 / 3, 2.0 / 3, 2.0 / 3} // linear
+				if dt > 0 {
+					norm[0], norm[2] = (bz[0]-k0.time)/dt, (bz[2]-k0.time)/dt
+				}
+				if dv != 0 {
+					norm[1], norm[3] = (bz[1]-k0.values[c])/dv, (bz[3]-k0.values[c])/dv
+				}
+				k0.curve.bezier[c] = norm
+			}
+		}
+	}
+	a.Duration = math.Max(a.Duration, tl.keys[len(tl.keys)-1].time)
+	a.timelines = append(a.timelines, tl)
+	return nil
+}
+
+// parseCurve parses the curve of a key. Curves of Spine 3.7 are [cx1, cy1,
+// cx2, cy2], curves of Spine 3.8 are cx1 with the fields c2, c3 and c4, both
+// normalized. Curves of Spine 4.x are 4 control points of each value, in time
+// and value, which are normalized by addTimeline.
+func parseCurve(jk *jsonKey, channels int, spine4 bool) (c curve, err error) {
+	if len(jk.Curve) == 0 {
+		return
+	}
+	var v interface{}
+	if err = json.Unmarshal(jk.Curve, &v); err != nil {
+		return
+	}
+	switch v := v.(type) {
+	case string:
+		c.stepped = v == "stepped"
+	case float64: // Spine 3.8
+		bz := [4]float64{v, jk.C2, jk.C3, jk.C4}
+		c.bezier = repeatCurve(bz, channels)
+	case []interface{}:
+		vals := make([]float64, len(v))
+		for i, e := range v {
+			f, ok := e.(float64)
+			if !ok {
+				return c, fmt.Errorf("invalid curve")
+			}
+			vals[i] = f
+		}
+		if spine4 {
+			if len(vals) < channels*4 {
+				return c, fmt.Errorf("invalid curve")
+			}
+			c.bezier = make([][4]float64, channels)
+			for i := range c.bezier {
+				copy(c.bezier[i][:], vals[i*4:])
+			}
+			return
+		}
+		if len(vals) != 4 {
+			return c, fmt.Errorf("invalid curve")
+		}
+		c.bezier = repeatCurve([4]float64{vals[0], vals[1], vals[2], vals[3]}, channels)
+	}
+	return
+}
+
+func repeatCurve(bz [4]float64, channels int) [][4]float64 {
+	ret := make([][4]float64, channels)
+	for i := range ret {
+		ret[i] = bz
+	}
+	return ret
+}
+
+// -------------------------------------------------------------------------------------
+
+// sample returns the values of the timeline at time t.
+func (p *timeline) sample(t float64, wrapAngles bool) (values [4]float64) {
+	keys := p.keys
+	i := sort.Search(len(keys), func(i int) bool { return keys[i].time > t }) - 1
+	if i < 0 {
+		return keys[0].values
+	}
+	k0 := keys[i]
+	if i+1 >= len(keys) || k0.curve.stepped {
+		return k0.values
+	}
+	k1 := keys[i+1]
+	x := (t - k0.time) / (k1.time - k0.time)
+	for c := 0; c < p.channels(); c++ {
+		pct := x
+		if k0.curve.bezier != nil {
+			pct = bezierAt(k0.curve.bezier[c], x)
+		}
+		v0, v1 := k0.values[c], k1.values[c]
+		if wrapAngles && p.kind == rotateTimeline {
+			v1 = v0 + wrapAngle(v1-v0)
+		}
+		values[c] = v0 + (v1-v0)*pct
+	}
+	return
+}
+
+// wrapAngle returns the angle in degrees in [-180, 180).
+func wrapAngle(deg float64) float64 {
+	return deg - 360*math.Floor((deg+180)/360)
+}
+
+// bezierAt returns y of the point of the cubic bezier curve from (0, 0) to
+// (1, 1) whose x is x.
+func bezierAt(bz [4]float64, x float64) float64 {
+	cubic := func(p1, p2, t float64) float64 {
+		u := 1 - t
+		return 3*u*u*t*p1 + 3*u*t*t*p2 + t*t*t
+	}
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 40; i++ {
+		mid := (lo + hi) / 2
+		if cubic(bz[0], bz[2], mid) < x {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return cubic(bz[1], bz[3], (lo+hi)/2)
+}
+
+// -------------------------------------------------------------------------------------
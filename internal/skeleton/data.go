@@ -0,0 +1,347 @@
+// Package skeleton is a runtime of skeletal animations exported by Spine
+// (http://esotericsoftware.com) in JSON, version 3.x or 4.x. It supports bones,
+// slots, region and mesh attachments of skins, and animations of bone
+// rotations, translations, scales and shears, and of slot attachments and
+// colors. Constraints, deforms, events and draw order timelines are ignored.
+package skeleton
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Color is a RGBA color, each component from 0 to 1.
+type Color struct {
+	R, G, B, A float64
+}
+
+var white = Color{1, 1, 1, 1}
+
+// BoneData is the setup pose of a bone.
+type BoneData struct {
+	Name   string
+	Parent int // index of the parent bone, -1 for the root bone
+
+	X, Y, Rotation, ScaleX, ScaleY, ShearX, ShearY float64
+}
+
+// SlotData is the setup pose of a slot.
+type SlotData struct {
+	Name       string
+	Bone       int
+	Attachment string
+	Color      Color
+}
+
+// BoneWeight is the influence of a bone on a vertex of a weighted mesh.
+type BoneWeight struct {
+	Bone   int
+	X, Y   float64 // position of the vertex in the bone space
+	Weight float64
+}
+
+// Attachment is an image attached to a slot. A region attachment is a mesh of
+// 2 triangles.
+type Attachment struct {
+	Name string
+	Path string // of the image, without extension
+
+	// Vertices are (x, y) pairs in the space of the slot bone, or nil if the
+	// mesh is weighted.
+	Vertices  []float64
+	Weights   [][]BoneWeight // bones influencing each vertex of a weighted mesh
+	UVs       []float64      // texture coordinates of vertices, from 0 to 1
+	Triangles []uint16
+	Color     Color
+}
+
+// Skin is a set of attachments, by slots and names.
+type Skin map[int]map[string]*Attachment
+
+// Data is the setup pose and animations of a skeleton.
+type Data struct {
+	Images     string // images directory
+	Bones      []*BoneData
+	Slots      []*SlotData
+	Skins      map[string]Skin
+	Animations map[string]*Animation
+}
+
+func (p *Data) boneIndex(name string) int {
+	for i, b := range p.Bones {
+		if b.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *Data) slotIndex(name string) int {
+	for i, s := range p.Slots {
+		if s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// -------------------------------------------------------------------------------------
+
+type jsonBone struct {
+	Name     string   `json:"name"`
+	Parent   string   `json:"parent"`
+	X        float64  `json:"x"`
+	Y        float64  `json:"y"`
+	Rotation float64  `json:"rotation"`
+	ScaleX   *float64 `json:"scaleX"`
+	ScaleY   *float64 `json:"scaleY"`
+	ShearX   float64  `json:"shearX"`
+	ShearY   float64  `json:"shearY"`
+}
+
+type jsonSlot struct {
+	Name       string `json:"name"`
+	Bone       string `json:"bone"`
+	Attachment string `json:"attachment"`
+	Color      string `json:"color"`
+}
+
+type jsonAttachment struct {
+	Type      string    `json:"type"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	X         float64   `json:"x"`
+	Y         float64   `json:"y"`
+	Rotation  float64   `json:"rotation"`
+	ScaleX    *float64  `json:"scaleX"`
+	ScaleY    *float64  `json:"scaleY"`
+	Width     float64   `json:"width"`
+	Height    float64   `json:"height"`
+	UVs       []float64 `json:"uvs"`
+	Triangles []uint16  `json:"triangles"`
+	Vertices  []float64 `json:"vertices"`
+	Color     string    `json:"color"`
+}
+
+type jsonSkin struct {
+	Name        string                                `json:"name"`
+	Attachments map[string]map[string]*jsonAttachment `json:"attachments"`
+}
+
+type jsonSkeleton struct {
+	Skeleton struct {
+		Spine  string `json:"spine"`
+		Images string `json:"images"`
+	} `json:"skeleton"`
+	Bones      []*jsonBone               `json:"bones"`
+	Slots      []*jsonSlot               `json:"slots"`
+	Skins      json.RawMessage           `json:"skins"`
+	Animations map[string]*jsonAnimation `json:"animations"`
+}
+
+// OpenFunc opens a file by its path relative to the root of a file system.
+type OpenFunc = func(name string) (io.ReadCloser, error)
+
+// Load loads the skeleton file name.
+func Load(open OpenFunc, name string) (*Data, error) {
+	f, err := open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	d, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("skeleton: %s: %w", name, err)
+	}
+	return d, nil
+}
+
+// Parse parses a skeleton in Spine JSON.
+func Parse(b []byte) (*Data, error) {
+	var js jsonSkeleton
+	if err := json.Unmarshal(b, &js); err != nil {
+		return nil, err
+	}
+	d := &Data{Images: js.Skeleton.Images}
+	for _, jb := range js.Bones {
+		bone := &BoneData{
+			Name: jb.Name, Parent: -1,
+			X: jb.X, Y: jb.Y, Rotation: jb.Rotation,
+			ScaleX: valueOr(jb.ScaleX, 1), ScaleY: valueOr(jb.ScaleY, 1),
+			ShearX: jb.ShearX, ShearY: jb.ShearY,
+		}
+		if jb.Parent != "" {
+			if bone.Parent = d.boneIndex(jb.Parent); bone.Parent < 0 {
+				return nil, fmt.Errorf("bone %s: parent %s not found", jb.Name, jb.Parent)
+			}
+		}
+		d.Bones = append(d.Bones, bone)
+	}
+	for _, jsl := range js.Slots {
+		bone := d.boneIndex(jsl.Bone)
+		if bone < 0 {
+			return nil, fmt.Errorf("slot %s: bone %s not found", jsl.Name, jsl.Bone)
+		}
+		color, err := parseColor(jsl.Color)
+		if err != nil {
+			return nil, err
+		}
+		d.Slots = append(d.Slots, &SlotData{Name: jsl.Name, Bone: bone, Attachment: jsl.Attachment, Color: color})
+	}
+	skins, err := parseSkins(js.Skins)
+	if err != nil {
+		return nil, err
+	}
+	d.Skins = make(map[string]Skin, len(skins))
+	for _, js := range skins {
+		skin := make(Skin)
+		for slotName, atts := range js.Attachments {
+			slot := d.slotIndex(slotName)
+			if slot < 0 {
+				return nil, fmt.Errorf("skin %s: slot %s not found", js.Name, slotName)
+			}
+			skin[slot] = make(map[string]*Attachment, len(atts))
+			for name, ja := range atts {
+				att, err := newAttachment(name, ja, len(d.Bones))
+				if err != nil {
+					return nil, fmt.Errorf("skin %s: attachment %s: %w", js.Name, name, err)
+				}
+				if att != nil {
+					skin[slot][name] = att
+				}
+			}
+		}
+		d.Skins[js.Name] = skin
+	}
+	spine4 := strings.HasPrefix(js.Skeleton.Spine, "4.")
+	d.Animations = make(map[string]*Animation, len(js.Animations))
+	for name, ja := range js.Animations {
+		a, err := newAnimation(d, name, ja, spine4)
+		if err != nil {
+			return nil, fmt.Errorf("animation %s: %w", name, err)
+		}
+		d.Animations[name] = a
+	}
+	return d, nil
+}
+
+// parseSkins parses skins in the array format of Spine 3.8 and later, or in
+// the object format of older versions.
+func parseSkins(b json.RawMessage) (skins []*jsonSkin, err error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return
+	}
+	if b[0] == '[' {
+		err = json.Unmarshal(b, &skins)
+		return
+	}
+	var m map[string]map[string]map[string]*jsonAttachment
+	if err = json.Unmarshal(b, &m); err != nil {
+		return
+	}
+	for name, atts := range m {
+		skins = append(skins, &jsonSkin{Name: name, Attachments: atts})
+	}
+	return
+}
+
+// newAttachment creates a region or mesh attachment, or returns nil for
+// attachments of other types, which are not drawn.
+func newAttachment(name string, ja *jsonAttachment, bones int) (*Attachment, error) {
+	if ja.Name != "" {
+		name = ja.Name
+	}
+	att := &Attachment{Name: name, Path: name}
+	if ja.Path != "" {
+		att.Path = ja.Path
+	}
+	color, err := parseColor(ja.Color)
+	if err != nil {
+		return nil, err
+	}
+	att.Color = color
+	switch ja.Type {
+	case "", "region":
+		sx, sy := valueOr(ja.ScaleX, 1), valueOr(ja.ScaleY, 1)
+		w, h := ja.Width/2*sx, ja.Height/2*sy
+		sin, cos := math.Sincos(ja.Rotation * math.Pi / 180)
+		corner := func(x, y float64) (float64, float64) {
+			return x*cos - y*sin + ja.X, x*sin + y*cos + ja.Y
+		}
+		att.Vertices = make([]float64, 0, 8)
+		for _, pt := range [][2]float64{{-w, -h}, {w, -h}, {w, h}, {-w, h}} {
+			x, y := corner(pt[0], pt[1])
+			att.Vertices = append(att.Vertices, x, y)
+		}
+		att.UVs = []float64{0, 1, 1, 1, 1, 0, 0, 0}
+		att.Triangles = []uint16{0, 1, 2, 2, 3, 0}
+	case "mesh":
+		n := len(ja.UVs) / 2
+		att.UVs, att.Triangles = ja.UVs, ja.Triangles
+		if len(ja.Vertices) == len(ja.UVs) {
+			att.Vertices = ja.Vertices
+			break
+		}
+		vs := ja.Vertices
+		att.Weights = make([][]BoneWeight, n)
+		for i := 0; i < n; i++ {
+			if len(vs) == 0 {
+				return nil, fmt.Errorf("too few vertices")
+			}
+			count := int(vs[0])
+			if len(vs) < 1+count*4 {
+				return nil, fmt.Errorf("too few vertices")
+			}
+			for j := 0; j < count; j++ {
+				v := vs[1+j*4:]
+				if bone := int(v[0]); bone < 0 || bone >= bones {
+					return nil, fmt.Errorf("bone %d out of range", bone)
+				}
+				att.Weights[i] = append(att.Weights[i], BoneWeight{Bone: int(v[0]), X: v[1], Y: v[2], Weight: v[3]})
+			}
+			vs = vs[1+count*4:]
+		}
+	default:
+		return nil, nil
+	}
+	for _, i := range att.Triangles {
+		if int(i) >= len(att.UVs)/2 {
+			return nil, fmt.Errorf("vertex %d out of range", i)
+		}
+	}
+	return att, nil
+}
+
+// parseColor parses a color in hex RRGGBBAA, or returns white if s is empty.
+func parseColor(s string) (Color, error) {
+	if s == "" {
+		return white, nil
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil || len(s) != 8 {
+		return Color{}, fmt.Errorf("invalid color %q", s)
+	}
+	return Color{
+		R: float64(v>>24) / 255, G: float64(v>>16&0xff) / 255,
+		B: float64(v>>8&0xff) / 255, A: float64(v&0xff) / 255,
+	}, nil
+}
+
+func valueOr(v *float64, def float64) float64 {
+	if v == nil {
+		return def
+	}
+	return *v
+}
+
+// -------------------------------------------------------------------------------------
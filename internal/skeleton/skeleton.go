@@ -0,0 +1,222 @@
+package skeleton
+
+import (
+	"math"
+	"sort"
+)
+
+// Bone is a bone of a skeleton in a pose.
+type Bone struct {
+	Data *BoneData
+
+	// local transform, relative to the parent bone
+	X, Y, Rotation, ScaleX, ScaleY, ShearX, ShearY float64
+
+	// world transform, in the skeleton space
+	a, b, c, d, worldX, worldY float64
+}
+
+// Slot is a slot of a skeleton in a pose.
+type Slot struct {
+	Data       *SlotData
+	Attachment *Attachment // nil if hidden
+	Color      Color
+}
+
+// Skeleton is an instance of skeleton data, in a pose.
+type Skeleton struct {
+	Data  *Data
+	Bones []*Bone
+	Slots []*Slot
+	skin  Skin
+}
+
+// New creates a skeleton in the setup pose, with the default skin.
+func New(d *Data) *Skeleton {
+	s := &Skeleton{Data: d, skin: d.Skins["default"]}
+	s.Bones = make([]*Bone, len(d.Bones))
+	for i, bd := range d.Bones {
+		s.Bones[i] = &Bone{Data: bd}
+	}
+	s.Slots = make([]*Slot, len(d.Slots))
+	for i, sd := range d.Slots {
+		s.Slots[i] = &Slot{Data: sd}
+	}
+	s.SetToSetupPose()
+	return s
+}
+
+// SetSkin sets the skin of the skeleton. Attachments not in the skin are
+// looked up in the default skin.
+func (p *Skeleton) SetSkin(name string) bool {
+	skin, ok := p.Data.Skins[name]
+	if ok {
+		p.skin = skin
+		p.setToSetupSlots()
+	}
+	return ok
+}
+
+func (p *Skeleton) attachment(slot int, name string) *Attachment {
+	if name == "" {
+		return nil
+	}
+	if att, ok := p.skin[slot][name]; ok {
+		return att
+	}
+	return p.Data.Skins["default"][slot][name]
+}
+
+// SetToSetupPose sets bones and slots to the setup pose.
+func (p *Skeleton) SetToSetupPose() {
+	for _, b := range p.Bones {
+		d := b.Data
+		b.X, b.Y, b.Rotation = d.X, d.Y, d.Rotation
+		b.ScaleX, b.ScaleY, b.ShearX, b.ShearY = d.ScaleX, d.ScaleY, d.ShearX, d.ShearY
+	}
+	p.setToSetupSlots()
+}
+
+func (p *Skeleton) setToSetupSlots() {
+	for i, s := range p.Slots {
+		s.Attachment = p.attachment(i, s.Data.Attachment)
+		s.Color = s.Data.Color
+	}
+}
+
+// Apply sets the skeleton to the pose of the animation at time t in seconds,
+// and updates the world transforms of bones.
+func (p *Skeleton) Apply(a *Animation, t float64) {
+	p.SetToSetupPose()
+	t = math.Max(0, math.Min(t, a.Duration))
+	for _, tl := range a.timelines {
+		v := tl.sample(t, a.wrapAngles)
+		if tl.kind == colorTimeline {
+			p.Slots[tl.index].Color = Color{v[0], v[1], v[2], v[3]}
+			continue
+		}
+		b := p.Bones[tl.index]
+		d := b.Data
+		switch tl.kind {
+		case rotateTimeline:
+			b.Rotation = d.Rotation + v[0]
+		case translateTimeline:
+			b.X, b.Y = d.X+v[0], d.Y+v[1]
+		case scaleTimeline:
+			b.ScaleX, b.ScaleY = d.ScaleX*v[0], d.ScaleY*v[1]
+		case shearTimeline:
+			b.ShearX, b.ShearY = d.ShearX+v[0], d.ShearY+v[1]
+		}
+	}
+	for _, at := range a.attachments {
+		keys := at.keys
+		i := sort.Search(len(keys), func(i int) bool { return keys[i].time > t }) - 1
+		if i >= 0 {
+			p.Slots[at.slot].Attachment = p.attachment(at.slot, keys[i].name)
+		}
+	}
+	p.UpdateWorldTransform()
+}
+
+// UpdateWorldTransform computes the world transforms of bones from their
+// local transforms.
+func (p *Skeleton) UpdateWorldTransform() {
+	for _, b := range p.Bones {
+		rx, ry := (b.Rotation+b.ShearX)*math.Pi/180, (b.Rotation+90+b.ShearY)*math.Pi/180
+		la, lb := math.Cos(rx)*b.ScaleX, math.Cos(ry)*b.ScaleY
+		lc, ld := math.Sin(rx)*b.ScaleX, math.Sin(ry)*b.ScaleY
+		if b.Data.Parent < 0 {
+			b.a, b.b, b.c, b.d = la, lb, lc, ld
+			b.worldX, b.worldY = b.X, b.Y
+			continue
+		}
+		parent := p.Bones[b.Data.Parent]
+		pa, pb, pc, pd := parent.a, parent.b, parent.c, parent.d
+		b.worldX = pa*b.X + pb*b.Y + parent.worldX
+		b.worldY = pc*b.X + pd*b.Y + parent.worldY
+		b.a, b.b = pa*la+pb*lc, pa*lb+pb*ld
+		b.c, b.d = pc*la+pd*lc, pc*lb+pd*ld
+	}
+}
+
+// LocalToWorld transforms a point from the bone space to the skeleton space.
+func (b *Bone) LocalToWorld(x, y float64) (float64, float64) {
+	return b.a*x + b.b*y + b.worldX, b.c*x + b.d*y + b.worldY
+}
+
+// -------------------------------------------------------------------------------------
+
+// Part is an attachment of a slot to draw, with vertices in the skeleton
+// space.
+type Part struct {
+	Image     string    // path of the image, without extension
+	Vertices  []float64 // (x, y) pairs
+	UVs       []float64
+	Triangles []uint16
+	Color     Color
+}
+
+// Parts returns the visible attachments of slots, in drawing order.
+func (p *Skeleton) Parts() []*Part {
+	parts := make([]*Part, 0, len(p.Slots))
+	for _, s := range p.Slots {
+		att := s.Attachment
+		if att == nil || s.Color.A == 0 {
+			continue
+		}
+		part := &Part{
+			Image: att.Path, UVs: att.UVs, Triangles: att.Triangles,
+			Color: Color{
+				R: s.Color.R * att.Color.R, G: s.Color.G * att.Color.G,
+				B: s.Color.B * att.Color.B, A: s.Color.A * att.Color.A,
+			},
+		}
+		n := len(att.UVs) / 2
+		part.Vertices = make([]float64, 0, n*2)
+		if att.Weights == nil {
+			bone := p.Bones[s.Data.Bone]
+			for i := 0; i < n; i++ {
+				x, y := bone.LocalToWorld(att.Vertices[i*2], att.Vertices[i*2+1])
+				part.Vertices = append(part.Vertices, x, y)
+			}
+		} else {
+			for _, ws := range att.Weights {
+				x, y := 0.0, 0.0
+				for _, w := range ws {
+					bx, by := p.Bones[w.Bone].LocalToWorld(w.X, w.Y)
+					x, y = x+bx*w.Weight, y+by*w.Weight
+				}
+				part.Vertices = append(part.Vertices, x, y)
+			}
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// Locate returns the texture coordinates of the point (x, y), if it is in a
+// triangle of the part. Triangles drawn later come first.
+func (p *Part) Locate(x, y float64) (u, v float64, ok bool) {
+	vs, tris := p.Vertices, p.Triangles
+	for i := len(tris) - 3; i >= 0; i -= 3 {
+		i0, i1, i2 := int(tris[i])*2, int(tris[i+1])*2, int(tris[i+2])*2
+		x0, y0, x1, y1, x2, y2 := vs[i0], vs[i0+1], vs[i1], vs[i1+1], vs[i2], vs[i2+1]
+		d := (y1-y2)*(x0-x2) + (x2-x1)*(y0-y2)
+		if d == 0 {
+			continue
+		}
+		l0 := ((y1-y2)*(x-x2) + (x2-x1)*(y-y2)) / d
+		l1 := ((y2-y0)*(x-x2) + (x0-x2)*(y-y2)) / d
+		l2 := 1 - l0 - l1
+		if l0 < 0 || l1 < 0 || l2 < 0 {
+			continue
+		}
+		uv := p.UVs
+		u = l0*uv[i0] + l1*uv[i1] + l2*uv[i2]
+		v = l0*uv[i0+1] + l1*uv[i1+1] + l2*uv[i2+1]
+		return u, v, true
+	}
+	return
+}
+
+// -------------------------------------------------------------------------------------
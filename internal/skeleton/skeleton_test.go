@@ -0,0 +1,158 @@
+package skeleton
+
+import (
+	"math"
+	"testing"
+)
+
+const spine4JSON = `{
+	"skeleton": {"spine": "4.1.20", "images": "./images/"},
+	"bones": [
+		{"name": "root"},
+		{"name": "arm", "parent": "root", "x": 10, "rotation": 90}
+	],
+	"slots": [
+		{"name": "hand", "bone": "arm", "attachment": "hand"},
+		{"name": "tail", "bone": "root", "attachment": "tail"}
+	],
+	"skins": [{"name": "default", "attachments": {
+		"hand": {"hand": {"width": 4, "height": 2}},
+		"tail": {"tail": {"type": "mesh", "path": "body/tail", "uvs": [0, 0, 1, 0, 0, 1], "triangles": [0, 1, 2],
+			"vertices": [1, 0, 1, 0, 1, 1, 1, 2, 0, 1, 2, 0, 1, 0, 0.5, 1, 2, 0, 0.5]}}
+	}}],
+	"animations": {
+		"wave": {
+			"bones": {"arm": {"rotate": [{"value": 0, "curve": [0.25, 0, 0.75, -90]}, {"time": 1, "value": -90}]}},
+			"slots": {"hand": {"attachment": [{"time": 0.5, "name": null}]}}
+		},
+		"ease": {
+			"bones": {"arm": {"translate": [{"curve": "stepped"}, {"time": 1, "x": 4, "y": 2}]}}
+		}
+	}
+}`
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func checkVertex(t *testing.T, part *Part, i int, x, y float64) {
+	t.Helper()
+	if !near(part.Vertices[i*2], x) || !near(part.Vertices[i*2+1], y) {
+		t.Fatalf("vertex %d of %s: (%v, %v), want (%v, %v)", i, part.Image, part.Vertices[i*2], part.Vertices[i*2+1], x, y)
+	}
+}
+
+func TestSetupPose(t *testing.T) {
+	d, err := Parse([]byte(spine4JSON))
+	if err != nil {
+		t.Fatal("Parse:", err)
+	}
+	if d.Images != "./images/" || len(d.Bones) != 2 || d.Bones[1].Parent != 0 {
+		t.Fatal("data:", d)
+	}
+	s := New(d)
+	s.UpdateWorldTransform()
+	parts := s.Parts()
+	if len(parts) != 2 || parts[0].Image != "hand" || parts[1].Image != "body/tail" {
+		t.Fatal("parts:", parts)
+	}
+	// the arm turns the hand 90 degrees counterclockwise
+	checkVertex(t, parts[0], 0, 11, -2)
+	checkVertex(t, parts[0], 2, 9, 2)
+	// weighted mesh, the last vertex is the middle of both bones
+	checkVertex(t, parts[1], 0, 1, 0)
+	checkVertex(t, parts[1], 1, 10, 2)
+	checkVertex(t, parts[1], 2, 5.5, 1)
+}
+
+func TestApply(t *testing.T) {
+	d, err := Parse([]byte(spine4JSON))
+	if err != nil {
+		t.Fatal("Parse:", err)
+	}
+	wave := d.Animations["wave"]
+	if wave == nil || wave.Duration != 1 {
+		t.Fatal("wave:", wave)
+	}
+	s := New(d)
+	// eased symmetrically
+	s.Apply(wave, 0.5)
+	if rot := s.Bones[1].Rotation; !near(rot, 45) {
+		t.Fatal("rotation:", rot)
+	}
+	s.Apply(wave, 0.25)
+	if rot := s.Bones[1].Rotation; rot <= 90-22.5 || rot >= 90 {
+		t.Fatal("eased rotation:", rot)
+	}
+	if s.Slots[0].Attachment == nil {
+		t.Fatal("hand hidden before its key")
+	}
+	s.Apply(wave, 1)
+	if s.Slots[0].Attachment != nil {
+		t.Fatal("hand not hidden")
+	}
+	s.Apply(wave, 0) // back to the setup pose
+	parts := s.Parts()
+	if parts[0].Image != "hand" {
+		t.Fatal("parts:", parts)
+	}
+	if u, v, ok := parts[0].Locate(10, 0); !ok || !near(u, 0.5) || !near(v, 0.5) {
+		t.Fatal("Locate(10, 0):", u, v, ok)
+	}
+	if _, _, ok := parts[0].Locate(13, 0); ok {
+		t.Fatal("Locate(13, 0): ok")
+	}
+
+	ease := d.Animations["ease"]
+	s.Apply(ease, 0.9)
+	if b := s.Bones[1]; b.X != 10 || b.Y != 0 {
+		t.Fatal("stepped:", b.X, b.Y)
+	}
+	s.Apply(ease, 1)
+	if b := s.Bones[1]; b.X != 14 || b.Y != 2 {
+		t.Fatal("stepped end:", b.X, b.Y)
+	}
+}
+
+func TestSpine3(t *testing.T) {
+	d, err := Parse([]byte(`{
+		"skeleton": {"spine": "3.7.94"},
+		"bones": [{"name": "root"}],
+		"slots": [{"name": "body", "bone": "root", "attachment": "body", "color": "ffffff80"}],
+		"skins": {"default": {"body": {"body": {"width": 2, "height": 2}}}},
+		"animations": {"spin": {"bones": {"root": {"rotate": [
+			{"time": 0, "angle": 350, "curve": [0.25, 0, 0.75, 1]},
+			{"time": 2, "angle": 10}
+		]}}}}
+	}`))
+	if err != nil {
+		t.Fatal("Parse:", err)
+	}
+	s := New(d)
+	if c := s.Slots[0].Color; !near(c.A, 128.0/255) {
+		t.Fatal("color:", c)
+	}
+	spin := d.Animations["spin"]
+	// turns 20 degrees the shortest way, eased symmetrically
+	s.Apply(spin, 1)
+	if rot := wrapAngle(s.Bones[0].Rotation); !near(rot, 0) {
+		t.Fatal("rotation:", rot)
+	}
+	s.Apply(spin, 0.5)
+	if rot := s.Bones[0].Rotation; rot <= 350 || rot >= 355 {
+		t.Fatal("eased rotation:", rot)
+	}
+}
+
+func TestBezierAt(t *testing.T) {
+	linear := [4]float64{1.0 / 3, 1.0 / 3, 2.0 / 3, 2.0 / 3}
+	for _, x := range []float64{0, 0.3, 0.5, 1} {
+		if y := bezierAt(linear, x); !near(y, x) {
+			t.Fatal("linear:", x, y)
+		}
+	}
+	easeIn := [4]float64{0.42, 0, 1, 1}
+	if y := bezierAt(easeIn, 0.5); y >= 0.5 {
+		t.Fatal("ease in:", y)
+	}
+}
@@ -0,0 +1,164 @@
+package spatial
+
+import (
+	"math"
+)
+
+// Contact describes how two overlapping shapes penetrate each other.
+type Contact struct {
+	NX, NY float64 // unit normal, pointing from the first shape to the second
+	Depth  float64 // penetration depth along the normal
+}
+
+// Collide returns the contact of two shapes, or false if they don't overlap.
+// Moving the second shape by Depth along the normal separates them.
+func Collide(a, b Shape) (Contact, bool) {
+	ba, bb := a.Box(), b.Box()
+	if !ba.Overlaps(&bb) {
+		return Contact{}, false
+	}
+	switch a := a.(type) {
+	case *Circle:
+		switch b := b.(type) {
+		case *Circle:
+			return collideCircles(a, b)
+		case Polygon:
+			return collideCirclePolygon(a, b)
+		}
+	case Polygon:
+		switch b := b.(type) {
+		case *Circle:
+			c, ok := collideCirclePolygon(b, a)
+			c.NX, c.NY = -c.NX, -c.NY
+			return c, ok
+		case Polygon:
+			return collidePolygons(a, b)
+		}
+	}
+	panic("spatial.Collide: unexpected shape")
+}
+
+func collideCircles(a, b *Circle) (Contact, bool) {
+	dx, dy, r := b.X-a.X, b.Y-a.Y, a.R+b.R
+	d2 := dx*dx + dy*dy
+	if d2 > r*r {
+		return Contact{}, false
+	}
+	d := math.Sqrt(d2)
+	if d == 0 {
+		return Contact{NX: 0, NY: 1, Depth: r}, true
+	}
+	return Contact{NX: dx / d, NY: dy / d, Depth: r - d}, true
+}
+
+// collideCirclePolygon returns the contact of c and p, with the normal
+// pointing from c to p.
+func collideCirclePolygon(c *Circle, p Polygon) (Contact, bool) {
+	n := len(p)
+	if n < 3 {
+		return Contact{}, false
+	}
+	if p.Contains(c.X, c.Y) {
+		// push the circle out through the nearest edge
+		cx, cy := p.centroid()
+		ret := Contact{Depth: math.Inf(1)}
+		for i := 0; i < n; i++ {
+			a, b := p[i], p[(i+1)%n]
+			nx, ny := edgeNormal(a, b, cx, cy)
+			if d := (c.X-a.X)*nx + (c.Y-a.Y)*ny; -d < ret.Depth {
+				ret = Contact{NX: -nx, NY: -ny, Depth: -d}
+			}
+		}
+		ret.Depth += c.R
+		return ret, true
+	}
+	best, bx, by := math.Inf(1), 0.0, 0.0
+	for i := 0; i < n; i++ {
+		x, y := closestOnSegment(c.X, c.Y, p[i], p[(i+1)%n])
+		if d2 := (x-c.X)*(x-c.X) + (y-c.Y)*(y-c.Y); d2 < best {
+			best, bx, by = d2, x, y
+		}
+	}
+	if best > c.R*c.R {
+		return Contact{}, false
+	}
+	d := math.Sqrt(best)
+	if d == 0 {
+		cx, cy := p.centroid()
+		nx, ny := normalize(cx-c.X, cy-c.Y)
+		return Contact{NX: nx, NY: ny, Depth: c.R}, true
+	}
+	return Contact{NX: (bx - c.X) / d, NY: (by - c.Y) / d, Depth: c.R - d}, true
+}
+
+func collidePolygons(a, b Polygon) (Contact, bool) {
+	ret := Contact{Depth: math.Inf(1)}
+	if !a.minOverlap(b, &ret) || !b.minOverlap(a, &ret) {
+		return Contact{}, false
+	}
+	ax, ay := a.centroid()
+	bx, by := b.centroid()
+	if (bx-ax)*ret.NX+(by-ay)*ret.NY < 0 {
+		ret.NX, ret.NY = -ret.NX, -ret.NY
+	}
+	return ret, true
+}
+
+// minOverlap updates c with the edge normal of p along which p and q overlap
+// the least. It returns false if an edge normal separates them.
+func (p Polygon) minOverlap(q Polygon, c *Contact) bool {
+	n := len(p)
+	for i := 0; i < n; i++ {
+		a, b := p[i], p[(i+1)%n]
+		nx, ny := normalize(a.Y-b.Y, b.X-a.X)
+		if nx == 0 && ny == 0 {
+			continue
+		}
+		min1, max1 := p.project(nx, ny)
+		min2, max2 := q.project(nx, ny)
+		depth := math.Min(max1, max2) - math.Max(min1, min2)
+		if depth < 0 {
+			return false
+		}
+		if depth < c.Depth {
+			*c = Contact{NX: nx, NY: ny, Depth: depth}
+		}
+	}
+	return true
+}
+
+func (p Polygon) centroid() (x, y float64) {
+	for _, pt := range p {
+		x += pt.X
+		y += pt.Y
+	}
+	n := float64(len(p))
+	return x / n, y / n
+}
+
+// edgeNormal returns the unit normal of the edge ab of a convex polygon,
+// pointing away from its centroid (cx, cy).
+func edgeNormal(a, b Point, cx, cy float64) (nx, ny float64) {
+	nx, ny = normalize(a.Y-b.Y, b.X-a.X)
+	if (a.X-cx)*nx+(a.Y-cy)*ny < 0 {
+		nx, ny = -nx, -ny
+	}
+	return
+}
+
+func closestOnSegment(x, y float64, a, b Point) (float64, float64) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	t := 0.0
+	if l2 := dx*dx + dy*dy; l2 > 0 {
+		t = math.Max(0, math.Min(1, ((x-a.X)*dx+(y-a.Y)*dy)/l2))
+	}
+	return a.X + t*dx, a.Y + t*dy
+}
+
+func normalize(x, y float64) (float64, float64) {
+	l := math.Hypot(x, y)
+	if l == 0 {
+		return 0, 0
+	}
+	return x / l, y / l
+}
@@ -0,0 +1,138 @@
+// Package spatial implements spatial partitioning, to find objects which
+// bounding boxes overlap without testing every pair of them.
+package spatial
+
+import (
+	"math"
+	"sort"
+)
+
+// Box is an axis-aligned bounding box.
+type Box struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+// Overlaps checks if p and b overlap.
+func (p *Box) Overlaps(b *Box) bool {
+	return p.MinX <= b.MaxX && b.MinX <= p.MaxX && p.MinY <= b.MaxY && b.MinY <= p.MaxY
+}
+
+type cellKey struct {
+	x, y int
+}
+
+// Grid is a uniform grid. Each object is inserted into all cells its bounding
+// box overlaps, so objects should not be much larger than a cell.
+type Grid struct {
+	cellSize float64
+	cells    map[cellKey][]int
+	boxes    []Box
+	stamps   []int // to return each object once in Query
+	stamp    int
+}
+
+// NewGrid creates a grid with the specified cell size.
+func NewGrid(cellSize float64) *Grid {
+	p := &Grid{cells: make(map[cellKey][]int)}
+	p.Reset(cellSize)
+	return p
+}
+
+// Reset removes all objects and sets the cell size. The memory is kept for
+// reuse, so a grid can be rebuilt every frame cheaply.
+func (p *Grid) Reset(cellSize float64) {
+	if cellSize <= 0 {
+		cellSize = 1
+	}
+	if cellSize != p.cellSize {
+		p.cells = make(map[cellKey][]int)
+	}
+	p.cellSize = cellSize
+	for k, ids := range p.cells {
+		if len(ids) == 0 {
+			delete(p.cells, k) // not used in the last frame
+		} else {
+			p.cells[k] = ids[:0]
+		}
+	}
+	p.boxes = p.boxes[:0]
+	p.stamps = p.stamps[:0]
+}
+
+// Len returns the number of objects.
+func (p *Grid) Len() int {
+	return len(p.boxes)
+}
+
+// Box returns the bounding box of object id.
+func (p *Grid) Box(id int) *Box {
+	return &p.boxes[id]
+}
+
+func (p *Grid) cellOf(x, y float64) cellKey {
+	return cellKey{int(math.Floor(x / p.cellSize)), int(math.Floor(y / p.cellSize))}
+}
+
+// Insert adds an object with the bounding box b and returns its id. Ids are
+// assigned from 0 in order of insertion.
+func (p *Grid) Insert(b Box) int {
+	id := len(p.boxes)
+	p.boxes = append(p.boxes, b)
+	p.stamps = append(p.stamps, 0)
+	from, to := p.cellOf(b.MinX, b.MinY), p.cellOf(b.MaxX, b.MaxY)
+	for x := from.x; x <= to.x; x++ {
+		for y := from.y; y <= to.y; y++ {
+			k := cellKey{x, y}
+			p.cells[k] = append(p.cells[k], id)
+		}
+	}
+	return id
+}
+
+// Pairs calls fn once for each pair of objects which bounding boxes overlap,
+// with a < b, in increasing order of a then b.
+func (p *Grid) Pairs(fn func(a, b int)) {
+	var others []int
+	for a := range p.boxes {
+		ba := &p.boxes[a]
+		p.stamp++
+		others = others[:0]
+		from, to := p.cellOf(ba.MinX, ba.MinY), p.cellOf(ba.MaxX, ba.MaxY)
+		for x := from.x; x <= to.x; x++ {
+			for y := from.y; y <= to.y; y++ {
+				for _, b := range p.cells[cellKey{x, y}] {
+					if b <= a || p.stamps[b] == p.stamp {
+						continue
+					}
+					p.stamps[b] = p.stamp
+					if ba.Overlaps(&p.boxes[b]) {
+						others = append(others, b)
+					}
+				}
+			}
+		}
+		sort.Ints(others)
+		for _, b := range others {
+			fn(a, b)
+		}
+	}
+}
+
+// Query calls fn once for each object which bounding box overlaps b.
+func (p *Grid) Query(b Box, fn func(id int)) {
+	p.stamp++
+	from, to := p.cellOf(b.MinX, b.MinY), p.cellOf(b.MaxX, b.MaxY)
+	for x := from.x; x <= to.x; x++ {
+		for y := from.y; y <= to.y; y++ {
+			for _, id := range p.cells[cellKey{x, y}] {
+				if p.stamps[id] == p.stamp {
+					continue
+				}
+				p.stamps[id] = p.stamp
+				if b.Overlaps(&p.boxes[id]) {
+					fn(id)
+				}
+			}
+		}
+	}
+}
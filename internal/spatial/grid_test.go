@@ -0,0 +1,54 @@
+package spatial
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestGrid(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	g := NewGrid(32)
+	for i := 0; i < 200; i++ {
+		x, y := r.Float64()*600-300, r.Float64()*400-200
+		g.Insert(Box{x, y, x + r.Float64()*60, y + r.Float64()*60})
+	}
+
+	type pair struct{ a, b int }
+	found := make(map[pair]int)
+	last := pair{-1, -1}
+	g.Pairs(func(a, b int) {
+		if a < last.a || a == last.a && b <= last.b {
+			t.Fatalf("pair (%d, %d) reported after (%d, %d)", a, b, last.a, last.b)
+		}
+		last = pair{a, b}
+		found[pair{a, b}]++
+	})
+	n := 0
+	for a := 0; a < g.Len(); a++ {
+		for b := a + 1; b < g.Len(); b++ {
+			if g.Box(a).Overlaps(g.Box(b)) {
+				n++
+				if found[pair{a, b}] != 1 {
+					t.Fatalf("pair (%d, %d) reported %d times", a, b, found[pair{a, b}])
+				}
+			}
+		}
+	}
+	if n != len(found) {
+		t.Fatal("pairs:", len(found), "expected:", n)
+	}
+
+	b := Box{-50, -50, 50, 50}
+	seen := make(map[int]bool)
+	g.Query(b, func(id int) {
+		if seen[id] {
+			t.Fatal("Query: duplicated", id)
+		}
+		seen[id] = true
+	})
+	for id := 0; id < g.Len(); id++ {
+		if b.Overlaps(g.Box(id)) != seen[id] {
+			t.Fatal("Query: wrong result for", id)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package spatial
+
+import (
+	"math"
+)
+
+// RayHit describes where a ray crosses a shape.
+type RayHit struct {
+	Enter, Exit float64 // distances along the ray where it enters and exits the shape
+	NX, NY      float64 // unit normal of the surface at Enter
+}
+
+// Ray returns where the ray from (x, y) in the unit direction (dx, dy)
+// crosses s, or false if it misses s. If the ray starts inside s, Enter is 0
+// and the normal is opposite to the direction.
+func Ray(s Shape, x, y, dx, dy float64) (RayHit, bool) {
+	var hit RayHit
+	switch s := s.(type) {
+	case *Circle:
+		// solve |o + t*d - c| = r, with |d| = 1
+		ox, oy := x-s.X, y-s.Y
+		b := ox*dx + oy*dy
+		disc := b*b - (ox*ox + oy*oy - s.R*s.R)
+		if disc < 0 {
+			return hit, false
+		}
+		sq := math.Sqrt(disc)
+		hit.Enter, hit.Exit = -b-sq, -b+sq
+		hit.NX, hit.NY = normalize(x+hit.Enter*dx-s.X, y+hit.Enter*dy-s.Y)
+	case Polygon:
+		// clip the ray by the half planes of the edges (Cyrus-Beck)
+		n := len(s)
+		if n < 3 {
+			return hit, false
+		}
+		cx, cy := s.centroid()
+		hit.Enter, hit.Exit = math.Inf(-1), math.Inf(1)
+		for i := 0; i < n; i++ {
+			a, b := s[i], s[(i+1)%n]
+			nx, ny := edgeNormal(a, b, cx, cy)
+			dist := (x-a.X)*nx + (y-a.Y)*ny // > 0 outside of the edge
+			speed := dx*nx + dy*ny
+			if speed == 0 {
+				if dist > 0 {
+					return hit, false
+				}
+				continue
+			}
+			t := -dist / speed
+			if speed < 0 { // entering
+				if t > hit.Enter {
+					hit.Enter, hit.NX, hit.NY = t, nx, ny
+				}
+			} else if t < hit.Exit {
+				hit.Exit = t
+			}
+		}
+		if hit.Enter > hit.Exit {
+			return hit, false
+		}
+	default:
+		panic("spatial.Ray: unexpected shape")
+	}
+	if hit.Exit < 0 {
+		return hit, false
+	}
+	if hit.Enter < 0 {
+		hit.Enter, hit.NX, hit.NY = 0, -dx, -dy
+	}
+	return hit, true
+}
@@ -0,0 +1,136 @@
+package spatial
+
+import (
+	"math"
+)
+
+// Shape is a convex collision shape.
+type Shape interface {
+	Box() Box
+	Contains(x, y float64) bool
+}
+
+type Point struct {
+	X, Y float64
+}
+
+// Circle is a circle centered at (X, Y) with the radius R.
+type Circle struct {
+	X, Y, R float64
+}
+
+func (p *Circle) Box() Box {
+	return Box{p.X - p.R, p.Y - p.R, p.X + p.R, p.Y + p.R}
+}
+
+func (p *Circle) Contains(x, y float64) bool {
+	dx, dy := x-p.X, y-p.Y
+	return dx*dx+dy*dy <= p.R*p.R
+}
+
+// Polygon is a convex polygon, its points can be in any winding order.
+type Polygon []Point
+
+func (p Polygon) Box() Box {
+	if len(p) == 0 {
+		return Box{}
+	}
+	b := Box{p[0].X, p[0].Y, p[0].X, p[0].Y}
+	for _, pt := range p[1:] {
+		b.MinX, b.MinY = math.Min(b.MinX, pt.X), math.Min(b.MinY, pt.Y)
+		b.MaxX, b.MaxY = math.Max(b.MaxX, pt.X), math.Max(b.MaxY, pt.Y)
+	}
+	return b
+}
+
+func (p Polygon) Contains(x, y float64) bool {
+	n := len(p)
+	if n < 3 {
+		return false
+	}
+	sign := 0.0
+	for i := 0; i < n; i++ {
+		a, b := p[i], p[(i+1)%n]
+		cross := (b.X-a.X)*(y-a.Y) - (b.Y-a.Y)*(x-a.X)
+		if cross == 0 {
+			continue
+		}
+		if sign == 0 {
+			sign = cross
+		} else if (cross > 0) != (sign > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p Polygon) project(ax, ay float64) (min, max float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, pt := range p {
+		v := pt.X*ax + pt.Y*ay
+		min, max = math.Min(min, v), math.Max(max, v)
+	}
+	return
+}
+
+// separated checks if an edge normal of p separates p and q.
+func (p Polygon) separated(q Polygon) bool {
+	n := len(p)
+	for i := 0; i < n; i++ {
+		a, b := p[i], p[(i+1)%n]
+		ax, ay := a.Y-b.Y, b.X-a.X // edge normal
+		min1, max1 := p.project(ax, ay)
+		min2, max2 := q.project(ax, ay)
+		if max1 < min2 || max2 < min1 {
+			return true
+		}
+	}
+	return false
+}
+
+// distToSegment2 returns the squared distance from (x, y) to the segment ab.
+func distToSegment2(x, y float64, a, b Point) float64 {
+	px, py := closestOnSegment(x, y, a, b)
+	px, py = px-x, py-y
+	return px*px + py*py
+}
+
+func circlePolygon(c *Circle, p Polygon) bool {
+	if p.Contains(c.X, c.Y) {
+		return true
+	}
+	n, r2 := len(p), c.R*c.R
+	for i := 0; i < n; i++ {
+		if distToSegment2(c.X, c.Y, p[i], p[(i+1)%n]) <= r2 {
+			return true
+		}
+	}
+	return false
+}
+
+// Overlap checks if two shapes overlap, by the separating axis theorem for
+// polygons.
+func Overlap(a, b Shape) bool {
+	ba, bb := a.Box(), b.Box()
+	if !ba.Overlaps(&bb) {
+		return false
+	}
+	switch a := a.(type) {
+	case *Circle:
+		switch b := b.(type) {
+		case *Circle:
+			dx, dy, r := a.X-b.X, a.Y-b.Y, a.R+b.R
+			return dx*dx+dy*dy <= r*r
+		case Polygon:
+			return circlePolygon(a, b)
+		}
+	case Polygon:
+		switch b := b.(type) {
+		case *Circle:
+			return circlePolygon(b, a)
+		case Polygon:
+			return !a.separated(b) && !b.separated(a)
+		}
+	}
+	panic("spatial.Overlap: unexpected shape")
+}
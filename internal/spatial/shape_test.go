@@ -0,0 +1,92 @@
+package spatial
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOverlap(t *testing.T) {
+	square := Polygon{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	diamond := Polygon{{15, 5}, {20, 0}, {25, 5}, {20, 10}}
+	cases := []struct {
+		a, b Shape
+		want bool
+	}{
+		{square, diamond, false},
+		{square, Polygon{{9, 5}, {14, 0}, {19, 5}, {14, 10}}, true},
+		{&Circle{5, 5, 1}, square, true},    // inside
+		{&Circle{12, 5, 2.5}, square, true}, // crosses an edge
+		{&Circle{12, 12, 2}, square, false}, // near a corner, but apart
+		{&Circle{0, 0, 1}, &Circle{1.5, 0, 1}, true},
+		{&Circle{0, 0, 1}, &Circle{0, 2.5, 1}, false},
+	}
+	for i, c := range cases {
+		if got := Overlap(c.a, c.b); got != c.want {
+			t.Errorf("case %d: Overlap = %v", i, got)
+		}
+		if got := Overlap(c.b, c.a); got != c.want {
+			t.Errorf("case %d: Overlap (swapped) = %v", i, got)
+		}
+	}
+	if !square.Contains(5, 5) || square.Contains(11, 5) {
+		t.Error("Polygon.Contains")
+	}
+}
+
+func TestCollide(t *testing.T) {
+	square := Polygon{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	const eps = 1e-9
+	cases := []struct {
+		a, b          Shape
+		nx, ny, depth float64
+	}{
+		{square, Polygon{{8, 2}, {18, 2}, {18, 8}, {8, 8}}, 1, 0, 2},
+		{square, Polygon{{2, 9}, {8, 9}, {8, 19}, {2, 19}}, 0, 1, 1},
+		{&Circle{0, 0, 1}, &Circle{1.5, 0, 1}, 1, 0, 0.5},
+		{&Circle{5, 11, 2}, square, 0, -1, 1}, // above the square
+		{&Circle{5, 9, 2}, square, 0, -1, 3},  // center inside, near the top edge
+		{square, &Circle{-1, 5, 2}, -1, 0, 1}, // left of the square
+	}
+	for i, c := range cases {
+		ct, ok := Collide(c.a, c.b)
+		if !ok || math.Abs(ct.NX-c.nx) > eps || math.Abs(ct.NY-c.ny) > eps || math.Abs(ct.Depth-c.depth) > eps {
+			t.Errorf("case %d: Collide = %+v, %v", i, ct, ok)
+		}
+		ct, ok = Collide(c.b, c.a)
+		if !ok || math.Abs(ct.NX+c.nx) > eps || math.Abs(ct.NY+c.ny) > eps || math.Abs(ct.Depth-c.depth) > eps {
+			t.Errorf("case %d: Collide (swapped) = %+v, %v", i, ct, ok)
+		}
+	}
+	if _, ok := Collide(&Circle{12, 12, 2}, square); ok {
+		t.Error("Collide: circle near a corner")
+	}
+}
+
+func TestRay(t *testing.T) {
+	square := Polygon{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	const eps = 1e-9
+	cases := []struct {
+		s                   Shape
+		x, y, dx, dy        float64
+		enter, exit, nx, ny float64
+	}{
+		{square, -5, 5, 1, 0, 5, 15, -1, 0},
+		{square, 5, 20, 0, -1, 10, 20, 0, 1},
+		{square, 5, 5, 1, 0, 0, 5, -1, 0}, // from inside
+		{&Circle{0, 0, 2}, -5, 0, 1, 0, 3, 7, -1, 0},
+		{&Circle{0, 0, 2}, 0, 0, 0, 1, 0, 2, 0, -1},
+	}
+	for i, c := range cases {
+		hit, ok := Ray(c.s, c.x, c.y, c.dx, c.dy)
+		if !ok || math.Abs(hit.Enter-c.enter) > eps || math.Abs(hit.Exit-c.exit) > eps ||
+			math.Abs(hit.NX-c.nx) > eps || math.Abs(hit.NY-c.ny) > eps {
+			t.Errorf("case %d: Ray = %+v, %v", i, hit, ok)
+		}
+	}
+	if _, ok := Ray(square, -5, 5, -1, 0); ok {
+		t.Error("Ray: shape behind the ray")
+	}
+	if _, ok := Ray(&Circle{0, 0, 2}, -5, 3, 1, 0); ok {
+		t.Error("Ray: missing the circle")
+	}
+}
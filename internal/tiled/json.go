@@ -0,0 +1,192 @@
+package tiled
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+type jsonProperty struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+type jsonLayer struct {
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	Visible     *bool           `json:"visible"`
+	Opacity     *float64        `json:"opacity"`
+	OffsetX     float64         `json:"offsetx"`
+	OffsetY     float64         `json:"offsety"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Data        json.RawMessage `json:"data"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Properties  []*jsonProperty `json:"properties"`
+	Layers      []*jsonLayer    `json:"layers"`
+}
+
+type jsonTile struct {
+	ID         int             `json:"id"`
+	Properties []*jsonProperty `json:"properties"`
+}
+
+type jsonTileset struct {
+	FirstGID    int         `json:"firstgid"`
+	Source      string      `json:"source"`
+	Name        string      `json:"name"`
+	TileWidth   int         `json:"tilewidth"`
+	TileHeight  int         `json:"tileheight"`
+	Spacing     int         `json:"spacing"`
+	Margin      int         `json:"margin"`
+	Columns     int         `json:"columns"`
+	TileCount   int         `json:"tilecount"`
+	Image       string      `json:"image"`
+	ImageWidth  int         `json:"imagewidth"`
+	ImageHeight int         `json:"imageheight"`
+	Tiles       []*jsonTile `json:"tiles"`
+}
+
+type jsonMap struct {
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	TileWidth   int             `json:"tilewidth"`
+	TileHeight  int             `json:"tileheight"`
+	Orientation string          `json:"orientation"`
+	Infinite    bool            `json:"infinite"`
+	Properties  []*jsonProperty `json:"properties"`
+	Layers      []*jsonLayer    `json:"layers"`
+	Tilesets    []*jsonTileset  `json:"tilesets"`
+}
+
+func parseJSON(data []byte) (*Map, error) {
+	var jm jsonMap
+	if err := json.Unmarshal(data, &jm); err != nil {
+		return nil, err
+	}
+	if err := checkMap(jm.Orientation, jm.Infinite); err != nil {
+		return nil, err
+	}
+	m := &Map{
+		Width:      jm.Width,
+		Height:     jm.Height,
+		TileWidth:  jm.TileWidth,
+		TileHeight: jm.TileHeight,
+		Properties: jsonProperties(jm.Properties),
+	}
+	if err := m.addJSONLayers(jm.Layers, 0, 0, 1, true); err != nil {
+		return nil, err
+	}
+	for _, jts := range jm.Tilesets {
+		m.Tilesets = append(m.Tilesets, jsonTilesetOf(jts))
+	}
+	return m, nil
+}
+
+// addJSONLayers adds tile layers, flattening group layers which offset,
+// opacity and visibility apply to their children.
+func (p *Map) addJSONLayers(layers []*jsonLayer, dx, dy, opacity float64, visible bool) error {
+	for _, jl := range layers {
+		layerVisible := visible && (jl.Visible == nil || *jl.Visible)
+		layerOpacity := opacity
+		if jl.Opacity != nil {
+			layerOpacity *= *jl.Opacity
+		}
+		switch jl.Type {
+		case "group":
+			err := p.addJSONLayers(jl.Layers, dx+jl.OffsetX, dy+jl.OffsetY, layerOpacity, layerVisible)
+			if err != nil {
+				return err
+			}
+		case "tilelayer":
+			tiles, err := jsonLayerData(jl)
+			if err != nil {
+				return err
+			}
+			p.Layers = append(p.Layers, &Layer{
+				Name:       jl.Name,
+				Visible:    layerVisible,
+				Opacity:    layerOpacity,
+				OffsetX:    dx + jl.OffsetX,
+				OffsetY:    dy + jl.OffsetY,
+				Width:      jl.Width,
+				Height:     jl.Height,
+				Tiles:      tiles,
+				Properties: jsonProperties(jl.Properties),
+			})
+		}
+	}
+	return nil
+}
+
+func jsonLayerData(jl *jsonLayer) ([]uint32, error) {
+	n := jl.Width * jl.Height
+	if jl.Encoding == "base64" {
+		var s string
+		if err := json.Unmarshal(jl.Data, &s); err != nil {
+			return nil, err
+		}
+		return decodeData(jl.Encoding, jl.Compression, s, n)
+	}
+	var tiles []uint32
+	if err := json.Unmarshal(jl.Data, &tiles); err != nil {
+		return nil, errors.New("layer " + strconv.Quote(jl.Name) + ": " + err.Error())
+	}
+	return checkSize(tiles, n)
+}
+
+func jsonTilesetOf(jts *jsonTileset) *Tileset {
+	ts := &Tileset{
+		FirstGID:    jts.FirstGID,
+		Name:        jts.Name,
+		TileWidth:   jts.TileWidth,
+		TileHeight:  jts.TileHeight,
+		Spacing:     jts.Spacing,
+		Margin:      jts.Margin,
+		Columns:     jts.Columns,
+		TileCount:   jts.TileCount,
+		Image:       jts.Image,
+		ImageWidth:  jts.ImageWidth,
+		ImageHeight: jts.ImageHeight,
+		Tiles:       make(map[int]Properties),
+		Source:      jts.Source,
+	}
+	for _, tile := range jts.Tiles {
+		if props := jsonProperties(tile.Properties); props != nil {
+			ts.Tiles[tile.ID] = props
+		}
+	}
+	return ts
+}
+
+// parseTSJ parses an external tileset in JSON format.
+func parseTSJ(data []byte) (*Tileset, error) {
+	var jts jsonTileset
+	if err := json.Unmarshal(data, &jts); err != nil {
+		return nil, err
+	}
+	return jsonTilesetOf(&jts), nil
+}
+
+func jsonProperties(props []*jsonProperty) Properties {
+	if len(props) == 0 {
+		return nil
+	}
+	ret := make(Properties, len(props))
+	for _, prop := range props {
+		ret[prop.Name] = prop.Value
+	}
+	return ret
+}
+
+func checkMap(orientation string, infinite bool) error {
+	if orientation != "" && orientation != "orthogonal" {
+		return errors.New("unsupported orientation: " + orientation)
+	}
+	if infinite {
+		return errors.New("infinite maps are not supported")
+	}
+	return nil
+}
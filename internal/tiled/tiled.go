@@ -0,0 +1,271 @@
+// Package tiled loads maps created by the Tiled map editor (https://www.mapeditor.org),
+// in JSON (.tmj/.json) or XML (.tmx) format. Only orthogonal, finite maps with
+// tile layers are supported. Object and image layers are ignored and group
+// layers are flattened.
+package tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Flags stored in the high bits of a tile.
+const (
+	FlippedHorizontally = 0x80000000
+	FlippedVertically   = 0x40000000
+	FlippedDiagonally   = 0x20000000
+	rotatedHexagonal120 = 0x10000000
+
+	flagsMask = FlippedHorizontally | FlippedVertically | FlippedDiagonally | rotatedHexagonal120
+)
+
+// GID returns the global tile id of tile, without the flip flags.
+func GID(tile uint32) uint32 {
+	return tile &^ flagsMask
+}
+
+// Properties are custom properties of a map, layer or tile. Values are bool,
+// float64 (int and float properties) or string (others).
+type Properties map[string]interface{}
+
+type Map struct {
+	Width, Height         int // in tiles
+	TileWidth, TileHeight int // in pixels
+	Properties            Properties
+	Layers                []*Layer // tile layers, from bottom to top
+	Tilesets              []*Tileset
+}
+
+// Layer is a tile layer.
+type Layer struct {
+	Name             string
+	Visible          bool
+	Opacity          float64
+	OffsetX, OffsetY float64
+	Width, Height    int
+	Tiles            []uint32 // tiles row by row, 0 means empty
+	Properties       Properties
+}
+
+// Tile returns the tile at column x and row y, or 0 if out of range.
+func (p *Layer) Tile(x, y int) uint32 {
+	if x < 0 || y < 0 || x >= p.Width || y >= p.Height {
+		return 0
+	}
+	return p.Tiles[y*p.Width+x]
+}
+
+// SetTile sets the tile at column x and row y.
+func (p *Layer) SetTile(x, y int, tile uint32) bool {
+	if x < 0 || y < 0 || x >= p.Width || y >= p.Height {
+		return false
+	}
+	p.Tiles[y*p.Width+x] = tile
+	return true
+}
+
+type Tileset struct {
+	FirstGID                int
+	Name                    string
+	TileWidth, TileHeight   int
+	Spacing, Margin         int
+	Columns, TileCount      int
+	Image                   string // path of the image, relative to the root of the file system
+	ImageWidth, ImageHeight int
+	Tiles                   map[int]Properties // properties of tiles, by local tile id
+	Source                  string             // file of an external tileset, before it is loaded
+}
+
+// Contains checks if gid belongs to the tileset.
+func (p *Tileset) Contains(gid uint32) bool {
+	return int(gid) >= p.FirstGID && int(gid) < p.FirstGID+p.TileCount
+}
+
+// TileRect returns the rectangle of tile gid in the tileset image.
+func (p *Tileset) TileRect(gid uint32) (x, y, w, h int) {
+	id := int(gid) - p.FirstGID
+	cols := p.Columns
+	if cols <= 0 {
+		cols = 1
+	}
+	col, row := id%cols, id/cols
+	x = p.Margin + col*(p.TileWidth+p.Spacing)
+	y = p.Margin + row*(p.TileHeight+p.Spacing)
+	return x, y, p.TileWidth, p.TileHeight
+}
+
+func (p *Tileset) init() {
+	if p.Columns == 0 && p.TileWidth > 0 {
+		p.Columns = (p.ImageWidth - 2*p.Margin + p.Spacing) / (p.TileWidth + p.Spacing)
+	}
+	if p.TileCount == 0 && p.Columns > 0 && p.TileHeight > 0 {
+		rows := (p.ImageHeight - 2*p.Margin + p.Spacing) / (p.TileHeight + p.Spacing)
+		p.TileCount = p.Columns * rows
+	}
+}
+
+// Tileset returns the tileset which gid belongs to, or nil if not found.
+func (p *Map) Tileset(gid uint32) *Tileset {
+	gid = GID(gid)
+	for i := len(p.Tilesets) - 1; i >= 0; i-- {
+		if ts := p.Tilesets[i]; ts.Contains(gid) {
+			return ts
+		}
+	}
+	return nil
+}
+
+// TileProperties returns the properties of tile gid, or nil if it has none.
+func (p *Map) TileProperties(gid uint32) Properties {
+	gid = GID(gid)
+	if ts := p.Tileset(gid); ts != nil {
+		return ts.Tiles[int(gid)-ts.FirstGID]
+	}
+	return nil
+}
+
+// Layer returns the layer with the specified name, or nil if not found.
+func (p *Map) Layer(name string) *Layer {
+	for _, layer := range p.Layers {
+		if layer.Name == name {
+			return layer
+		}
+	}
+	return nil
+}
+
+// -------------------------------------------------------------------------------------
+
+// OpenFunc opens a file by its path relative to the root of a file system.
+type OpenFunc = func(name string) (io.ReadCloser, error)
+
+// Load loads the map file name. The format is detected by the file extension:
+// .tmx is XML, others are JSON.
+func Load(open OpenFunc, name string) (m *Map, err error) {
+	data, err := readFile(open, name)
+	if err != nil {
+		return
+	}
+	if strings.EqualFold(path.Ext(name), ".tmx") {
+		m, err = parseTMX(data)
+	} else {
+		m, err = parseJSON(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tiled: %s: %w", name, err)
+	}
+	dir := path.Dir(name)
+	for i, ts := range m.Tilesets {
+		if ts.Source != "" { // external tileset
+			if m.Tilesets[i], err = loadTileset(open, path.Join(dir, ts.Source), ts.FirstGID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		ts.Image = path.Join(dir, ts.Image)
+		ts.init()
+	}
+	return
+}
+
+func loadTileset(open OpenFunc, name string, firstGID int) (ts *Tileset, err error) {
+	data, err := readFile(open, name)
+	if err != nil {
+		return
+	}
+	if strings.EqualFold(path.Ext(name), ".tsx") {
+		ts, err = parseTSX(data)
+	} else {
+		ts, err = parseTSJ(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tiled: %s: %w", name, err)
+	}
+	ts.FirstGID = firstGID
+	ts.Image = path.Join(path.Dir(name), ts.Image)
+	ts.init()
+	return
+}
+
+func readFile(open OpenFunc, name string) ([]byte, error) {
+	f, err := open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// -------------------------------------------------------------------------------------
+
+// decodeData decodes layer data in csv or base64 encoding, optionally
+// compressed by zlib or gzip.
+func decodeData(encoding, compression, data string, n int) ([]uint32, error) {
+	switch encoding {
+	case "csv":
+		tiles := make([]uint32, 0, n)
+		for _, s := range strings.Split(data, ",") {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			v, err := strconv.ParseUint(s, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			tiles = append(tiles, uint32(v))
+		}
+		return checkSize(tiles, n)
+	case "base64":
+		b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+		if err != nil {
+			return nil, err
+		}
+		var r io.Reader = bytes.NewReader(b)
+		switch compression {
+		case "":
+		case "zlib":
+			if r, err = zlib.NewReader(r); err != nil {
+				return nil, err
+			}
+		case "gzip":
+			if r, err = gzip.NewReader(r); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.New("unsupported compression: " + compression)
+		}
+		tiles := make([]uint32, n)
+		if err = binary.Read(r, binary.LittleEndian, tiles); err != nil {
+			return nil, err
+		}
+		return tiles, nil
+	}
+	return nil, errors.New("unsupported encoding: " + encoding)
+}
+
+func checkSize(tiles []uint32, n int) ([]uint32, error) {
+	if len(tiles) != n {
+		return nil, fmt.Errorf("layer data has %d tiles, %d expected", len(tiles), n)
+	}
+	return tiles, nil
+}
+
+func propertyValue(typ, value string) interface{} {
+	switch typ {
+	case "bool":
+		return value == "true"
+	case "int", "float", "object":
+		v, _ := strconv.ParseFloat(value, 64)
+		return v
+	}
+	return value
+}
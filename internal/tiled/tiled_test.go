@@ -0,0 +1,106 @@
+package tiled
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"os"
+	"testing"
+)
+
+func openFiles(files map[string]string) OpenFunc {
+	return func(name string) (io.ReadCloser, error) {
+		if data, ok := files[name]; ok {
+			return io.NopCloser(bytes.NewReader([]byte(data))), nil
+		}
+		return nil, os.ErrNotExist
+	}
+}
+
+func checkMapLoaded(t *testing.T, m *Map) {
+	if m.Width != 3 || m.Height != 2 || m.TileWidth != 16 || m.TileHeight != 16 {
+		t.Fatal("map size:", m.Width, m.Height, m.TileWidth, m.TileHeight)
+	}
+	if len(m.Layers) != 2 {
+		t.Fatal("len(layers):", len(m.Layers))
+	}
+	walls := m.Layer("walls")
+	if walls == nil || walls.OffsetX != 8 || walls.Properties["collision"] != true {
+		t.Fatal("walls:", walls)
+	}
+	if tile := walls.Tile(2, 1); GID(tile) != 3 || tile&FlippedHorizontally == 0 {
+		t.Fatal("walls.Tile(2, 1):", tile)
+	}
+	ts := m.Tileset(3)
+	if ts == nil || ts.Image != "maps/img/tiles.png" || ts.Columns != 4 || ts.TileCount != 8 {
+		t.Fatal("tileset:", ts)
+	}
+	if x, y, _, _ := ts.TileRect(6); x != 17 || y != 17 {
+		t.Fatal("TileRect(6):", x, y)
+	}
+	if m.TileProperties(2)["solid"] != true {
+		t.Fatal("TileProperties(2):", m.TileProperties(2))
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	open := openFiles(map[string]string{
+		"maps/level.tmj": `{
+			"width": 3, "height": 2, "tilewidth": 16, "tileheight": 16, "orientation": "orthogonal",
+			"layers": [
+				{"type": "tilelayer", "name": "ground", "width": 3, "height": 2, "data": [1, 1, 1, 1, 1, 1]},
+				{"type": "group", "offsetx": 8, "layers": [
+					{"type": "tilelayer", "name": "walls", "width": 3, "height": 2, "data": [0, 2, 0, 0, 0, 2147483651],
+					 "properties": [{"name": "collision", "type": "bool", "value": true}]},
+					{"type": "objectgroup", "name": "objects"}
+				]}
+			],
+			"tilesets": [{"firstgid": 1, "source": "tiles.tsj"}]
+		}`,
+		"maps/tiles.tsj": `{
+			"name": "tiles", "tilewidth": 16, "tileheight": 16, "margin": 1,
+			"image": "img/tiles.png", "imagewidth": 66, "imageheight": 34,
+			"tiles": [{"id": 1, "properties": [{"name": "solid", "type": "bool", "value": true}]}]
+		}`,
+	})
+	m, err := Load(open, "maps/level.tmj")
+	if err != nil {
+		t.Fatal("Load:", err)
+	}
+	checkMapLoaded(t, m)
+}
+
+func TestLoadTMX(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	binary.Write(zw, binary.LittleEndian, []uint32{0, 2, 0, 0, 0, 3 | FlippedHorizontally})
+	zw.Close()
+	walls := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	open := openFiles(map[string]string{
+		"maps/level.tmx": `<?xml version="1.0" encoding="UTF-8"?>
+<map orientation="orthogonal" width="3" height="2" tilewidth="16" tileheight="16">
+ <tileset firstgid="1" name="tiles" tilewidth="16" tileheight="16" margin="1">
+  <image source="img/tiles.png" width="66" height="34"/>
+  <tile id="1"><properties><property name="solid" type="bool" value="true"/></properties></tile>
+ </tileset>
+ <layer name="ground" width="3" height="2"><data encoding="csv">
+1,1,1,
+1,1,1
+</data></layer>
+ <group offsetx="8">
+  <layer name="walls" width="3" height="2">
+   <properties><property name="collision" type="bool" value="true"/></properties>
+   <data encoding="base64" compression="zlib">` + walls + `</data>
+  </layer>
+ </group>
+</map>`,
+	})
+	m, err := Load(open, "maps/level.tmx")
+	if err != nil {
+		t.Fatal("Load:", err)
+	}
+	checkMapLoaded(t, m)
+}
@@ -0,0 +1,181 @@
+package tiled
+
+import (
+	"encoding/xml"
+	"errors"
+	"strconv"
+)
+
+// xmlNode is a generic XML element, so that the order of layers and groups is
+// kept.
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Text    string     `xml:",chardata"`
+	Nodes   []*xmlNode `xml:",any"`
+}
+
+func (p *xmlNode) attr(name string) string {
+	for _, attr := range p.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func (p *xmlNode) attrInt(name string) int {
+	v, _ := strconv.Atoi(p.attr(name))
+	return v
+}
+
+func (p *xmlNode) attrFloat(name string, def float64) float64 {
+	if v, err := strconv.ParseFloat(p.attr(name), 64); err == nil {
+		return v
+	}
+	return def
+}
+
+func (p *xmlNode) child(name string) *xmlNode {
+	for _, node := range p.Nodes {
+		if node.XMLName.Local == name {
+			return node
+		}
+	}
+	return nil
+}
+
+func (p *xmlNode) properties() Properties {
+	node := p.child("properties")
+	if node == nil || len(node.Nodes) == 0 {
+		return nil
+	}
+	ret := make(Properties, len(node.Nodes))
+	for _, prop := range node.Nodes {
+		value := prop.attr("value")
+		if value == "" {
+			value = prop.Text // multiline string properties
+		}
+		ret[prop.attr("name")] = propertyValue(prop.attr("type"), value)
+	}
+	return ret
+}
+
+func parseTMX(data []byte) (*Map, error) {
+	var root xmlNode
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if root.XMLName.Local != "map" {
+		return nil, errors.New("not a tmx file")
+	}
+	if err := checkMap(root.attr("orientation"), root.attr("infinite") == "1"); err != nil {
+		return nil, err
+	}
+	m := &Map{
+		Width:      root.attrInt("width"),
+		Height:     root.attrInt("height"),
+		TileWidth:  root.attrInt("tilewidth"),
+		TileHeight: root.attrInt("tileheight"),
+		Properties: root.properties(),
+	}
+	if err := m.addTMXLayers(&root, 0, 0, 1, true); err != nil {
+		return nil, err
+	}
+	for _, node := range root.Nodes {
+		if node.XMLName.Local == "tileset" {
+			ts := tmxTilesetOf(node)
+			ts.FirstGID = node.attrInt("firstgid")
+			ts.Source = node.attr("source")
+			m.Tilesets = append(m.Tilesets, ts)
+		}
+	}
+	return m, nil
+}
+
+// addTMXLayers adds tile layers, flattening group layers which offset,
+// opacity and visibility apply to their children.
+func (p *Map) addTMXLayers(parent *xmlNode, dx, dy, opacity float64, visible bool) error {
+	for _, node := range parent.Nodes {
+		layerVisible := visible && node.attr("visible") != "0"
+		layerOpacity := opacity * node.attrFloat("opacity", 1)
+		offsetX, offsetY := dx+node.attrFloat("offsetx", 0), dy+node.attrFloat("offsety", 0)
+		switch node.XMLName.Local {
+		case "group":
+			if err := p.addTMXLayers(node, offsetX, offsetY, layerOpacity, layerVisible); err != nil {
+				return err
+			}
+		case "layer":
+			layer := &Layer{
+				Name:       node.attr("name"),
+				Visible:    layerVisible,
+				Opacity:    layerOpacity,
+				OffsetX:    offsetX,
+				OffsetY:    offsetY,
+				Width:      node.attrInt("width"),
+				Height:     node.attrInt("height"),
+				Properties: node.properties(),
+			}
+			tiles, err := tmxLayerData(node.child("data"), layer.Width*layer.Height)
+			if err != nil {
+				return errors.New("layer " + strconv.Quote(layer.Name) + ": " + err.Error())
+			}
+			layer.Tiles = tiles
+			p.Layers = append(p.Layers, layer)
+		}
+	}
+	return nil
+}
+
+func tmxLayerData(data *xmlNode, n int) ([]uint32, error) {
+	if data == nil {
+		return nil, errors.New("no data")
+	}
+	if encoding := data.attr("encoding"); encoding != "" {
+		return decodeData(encoding, data.attr("compression"), data.Text, n)
+	}
+	tiles := make([]uint32, 0, n) // <tile gid="..."/> elements
+	for _, tile := range data.Nodes {
+		gid, _ := strconv.ParseUint(tile.attr("gid"), 10, 32)
+		tiles = append(tiles, uint32(gid))
+	}
+	return checkSize(tiles, n)
+}
+
+func tmxTilesetOf(node *xmlNode) *Tileset {
+	ts := &Tileset{
+		Name:       node.attr("name"),
+		TileWidth:  node.attrInt("tilewidth"),
+		TileHeight: node.attrInt("tileheight"),
+		Spacing:    node.attrInt("spacing"),
+		Margin:     node.attrInt("margin"),
+		Columns:    node.attrInt("columns"),
+		TileCount:  node.attrInt("tilecount"),
+		Tiles:      make(map[int]Properties),
+	}
+	if img := node.child("image"); img != nil {
+		ts.Image = img.attr("source")
+		ts.ImageWidth = img.attrInt("width")
+		ts.ImageHeight = img.attrInt("height")
+	}
+	for _, tile := range node.Nodes {
+		if tile.XMLName.Local == "tile" {
+			if props := tile.properties(); props != nil {
+				ts.Tiles[tile.attrInt("id")] = props
+			}
+		}
+	}
+	return ts
+}
+
+// parseTSX parses an external tileset in XML format.
+func parseTSX(data []byte) (*Tileset, error) {
+	var root xmlNode
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if root.XMLName.Local != "tileset" {
+		return nil, errors.New("not a tsx file")
+	}
+	return tmxTilesetOf(&root), nil
+}
@@ -43,7 +43,7 @@ type EasingFunction struct {
 /**
 * Sets the easing mode of the current function.
  */
-func (this *EasingFunction) setEasingMode(easingMode EasingMode) {
+func (this *EasingFunction) SetEasingMode(easingMode EasingMode) {
 	this.easingMode = easingMode
 }
 
@@ -156,3 +156,183 @@ func (this *BounceEase) EaseInCore(gradient float64) float64 {
 	var num2 = num7 - num8
 	return (((-math.Pow(1.0/bounciness, y-num3) / (num2 * num2)) * (num6 - num2)) * (num6 + num2))
 }
+
+/**
+ * Easing function with a power of 2 shape (see link below).
+ * @see https://easings.net/#easeInQuad
+ */
+type QuadraticEase struct {
+	EasingFunction
+}
+
+func NewQuadraticEase() *QuadraticEase {
+	return &QuadraticEase{}
+}
+
+func (this *QuadraticEase) EaseInCore(gradient float64) float64 {
+	return gradient * gradient
+}
+
+/**
+ * Easing function with a power of 3 shape (see link below).
+ * @see https://easings.net/#easeInCubic
+ */
+type CubicEase struct {
+	EasingFunction
+}
+
+func NewCubicEase() *CubicEase {
+	return &CubicEase{}
+}
+
+func (this *CubicEase) EaseInCore(gradient float64) float64 {
+	return gradient * gradient * gradient
+}
+
+/**
+ * Easing function with a power of 4 shape (see link below).
+ * @see https://easings.net/#easeInQuart
+ */
+type QuarticEase struct {
+	EasingFunction
+}
+
+func NewQuarticEase() *QuarticEase {
+	return &QuarticEase{}
+}
+
+func (this *QuarticEase) EaseInCore(gradient float64) float64 {
+	return gradient * gradient * gradient * gradient
+}
+
+/**
+ * Easing function with a power of 5 shape (see link below).
+ * @see https://easings.net/#easeInQuint
+ */
+type QuinticEase struct {
+	EasingFunction
+}
+
+func NewQuinticEase() *QuinticEase {
+	return &QuinticEase{}
+}
+
+func (this *QuinticEase) EaseInCore(gradient float64) float64 {
+	return gradient * gradient * gradient * gradient * gradient
+}
+
+/**
+ * Easing function with a sin shape (see link below).
+ * @see https://easings.net/#easeInSine
+ */
+type SineEase struct {
+	EasingFunction
+}
+
+func NewSineEase() *SineEase {
+	return &SineEase{}
+}
+
+func (this *SineEase) EaseInCore(gradient float64) float64 {
+	return (1.0 - math.Sin(1.5707963267948966*(1.0-gradient)))
+}
+
+/**
+ * Easing function with an exponential shape (see link below).
+ * @see https://easings.net/#easeInExpo
+ */
+type ExponentialEase struct {
+	EasingFunction
+
+	/** Defines the exponent of the function */
+	Exponent float64
+}
+
+func NewExponentialEase() *ExponentialEase {
+	return &ExponentialEase{
+		Exponent: 7.0,
+	}
+}
+
+func (this *ExponentialEase) EaseInCore(gradient float64) float64 {
+	if this.Exponent <= 0 {
+		return gradient
+	}
+	return ((math.Exp(this.Exponent*gradient) - 1.0) / (math.Exp(this.Exponent) - 1.0))
+}
+
+/**
+ * Easing function with an elastic shape (see link below).
+ * @see https://easings.net/#easeInElastic
+ */
+type ElasticEase struct {
+	EasingFunction
+
+	/** Defines the number of oscillations*/
+	Oscillations float64
+
+	/** Defines the amplitude of the oscillations*/
+	Springiness float64
+}
+
+func NewElasticEase() *ElasticEase {
+	return &ElasticEase{
+		Oscillations: 3.0,
+		Springiness:  3.0,
+	}
+}
+
+func (this *ElasticEase) EaseInCore(gradient float64) float64 {
+	var num2 float64
+	var num3 = math.Max(0.0, this.Oscillations)
+	var num = math.Max(0.0, this.Springiness)
+	if num == 0 {
+		num2 = gradient
+	} else {
+		num2 = (math.Exp(num*gradient) - 1.0) / (math.Exp(num) - 1.0)
+	}
+	return (num2 * math.Sin(((6.2831853071795862*num3)+1.5707963267948966)*gradient))
+}
+
+/**
+ * Easing function with a CSS-like cubic bezier shape, from (0, 0) to (1, 1)
+ * with the control points (X1, Y1) and (X2, Y2).
+ * @see https://developer.mozilla.org/en-US/docs/Web/CSS/easing-function#cubic_b%C3%A9zier_easing_function
+ */
+type BezierCurveEase struct {
+	EasingFunction
+
+	X1, Y1, X2, Y2 float64
+}
+
+func NewBezierCurveEase(x1, y1, x2, y2 float64) *BezierCurveEase {
+	return &BezierCurveEase{X1: x1, Y1: y1, X2: x2, Y2: y2}
+}
+
+func (this *BezierCurveEase) EaseInCore(gradient float64) float64 {
+	// find t where x(t) == gradient by Newton's method, then bisection
+	f0 := 1 - 3*this.X2 + 3*this.X1
+	f1 := 3*this.X2 - 6*this.X1
+	f2 := 3 * this.X1
+	x := func(t float64) float64 { return ((f0*t+f1)*t + f2) * t }
+	t := gradient
+	for i := 0; i < 8; i++ {
+		slope := (3*f0*t+2*f1)*t + f2
+		if math.Abs(slope) < 1e-6 {
+			break
+		}
+		t = math.Max(0, math.Min(1, t-(x(t)-gradient)/slope))
+	}
+	if math.Abs(x(t)-gradient) > 1e-6 {
+		lo, hi := 0.0, 1.0
+		for i := 0; i < 32; i++ {
+			t = (lo + hi) / 2
+			if x(t) < gradient {
+				lo = t
+			} else {
+				hi = t
+			}
+		}
+	}
+	return 3*(1-t)*(1-t)*t*this.Y1 + 3*(1-t)*t*t*this.Y2 + t*t*t
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBezierCurveEase(t *testing.T) {
+	ease := NewBezierCurveEase(0.25, 0.1, 0.25, 1) // CSS "ease"
+	for _, tc := range []struct{ x, y float64 }{
+		{0, 0},
+		{0.1, 0.0947963},
+		{0.25, 0.4085106},
+		{0.5, 0.8024034},
+		{0.75, 0.9604590},
+		{0.9, 0.9943165},
+		{1, 1},
+	} {
+		if y := ease.EaseInCore(tc.x); math.Abs(y-tc.y) > 1e-5 {
+			t.Fatal("ease:", tc.x, y, tc.y)
+		}
+	}
+}
+
+func TestBezierCurveEaseLinear(t *testing.T) {
+	ease := NewBezierCurveEase(0, 0, 1, 1)
+	for x := 0.0; x <= 1; x += 0.125 {
+		if y := ease.EaseInCore(x); math.Abs(y-x) > 1e-6 {
+			t.Fatal("linear:", x, y)
+		}
+	}
+}
+
+func TestBezierCurveEaseFlatSlope(t *testing.T) {
+	// x'(0.5) == 0, so Newton's method stalls and bisection has to finish
+	ease := NewBezierCurveEase(1, 0, 0, 1)
+	if y := ease.EaseInCore(0.5); math.Abs(y-0.5) > 1e-6 {
+		t.Fatal("middle:", y)
+	}
+	prev := -1.0
+	for x := 0.0; x <= 1; x += 1.0 / 64 {
+		y := ease.EaseInCore(x)
+		if y < prev-1e-9 || y < 0 || y > 1 {
+			t.Fatal("not monotonic:", x, y, prev)
+		}
+		prev = y
+	}
+	if y := ease.EaseInCore(0); y != 0 {
+		t.Fatal("start:", y)
+	}
+	if y := ease.EaseInCore(1); math.Abs(y-1) > 1e-9 {
+		t.Fatal("end:", y)
+	}
+}
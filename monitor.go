@@ -27,7 +27,6 @@ import (
 
 	"github.com/goplus/spx/internal/gdi"
 	xfont "github.com/goplus/spx/internal/gdi/font"
-	"github.com/goplus/spx/internal/tools"
 	"github.com/hajimehoshi/ebiten/v2"
 	"golang.org/x/image/font"
 )
@@ -36,17 +35,13 @@ import (
 
 // Monitor class.
 type Monitor struct {
-	game    *Game
-	name    WidgetName
-	size    float64
-	target  string
-	val     string
-	eval    func() string
-	mode    int
-	color   Color
-	x, y    float64
-	label   string
-	visible bool
+	widgetBase
+	target string
+	val    string
+	eval   func() string
+	mode   int
+	color  Color
+	label  string
 }
 
 /*
@@ -66,11 +61,6 @@ type Monitor struct {
 func newMonitor(g reflect.Value, v specsp) (*Monitor, error) {
 	target := v["target"].(string)
 	val := v["val"].(string)
-	name := v["name"].(string)
-	size := 1.0
-	if v["size"] != nil {
-		size, _ = tools.GetFloat(v["size"])
-	}
 	eval := buildMonitorEval(g, target, val)
 	if eval == nil {
 		return nil, syscall.ENOENT
@@ -81,13 +71,12 @@ func newMonitor(g reflect.Value, v specsp) (*Monitor, error) {
 		color = Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
 	}
 	label := v["label"].(string)
-	x := v["x"].(float64)
-	y := v["y"].(float64)
-	visible := v["visible"].(bool)
-	return &Monitor{
-		target: target, val: val, eval: eval, name: name, size: size,
-		visible: visible, mode: mode, color: color, x: x, y: y, label: label,
-	}, nil
+	p := &Monitor{
+		target: target, val: val, eval: eval,
+		mode: mode, color: color, label: label,
+	}
+	p.initWith(nil, v, 0, 0)
+	return p, nil
 }
 
 func getTarget(g reflect.Value, target string) (reflect.Value, int) {
@@ -178,8 +167,7 @@ func (p *Monitor) draw(dc drawContext) {
 		return
 	}
 	val := p.eval()
-	x, y := p.x, p.y
-	x, y = p.game.convertWinSpace2GameSpace(x, y)
+	x, y, _, _ := p.screenRect()
 	switch p.mode {
 	case 2:
 		render := gdi.NewTextRender(defaultFont, 0x80000, 0)
@@ -194,6 +182,7 @@ func (p *Monitor) draw(dc drawContext) {
 		if val != "" {
 			render.Draw(dc.Image, int(x+((w-textW)/2)), int(y), color.White, 0)
 		}
+		p.width, p.height = w/p.size, h/p.size
 	default:
 		font := getOrCreateFont(int(p.size * 12))
 		labelRender := gdi.NewTextRender(font, 0x80000, 0)
@@ -214,6 +203,7 @@ func (p *Monitor) draw(dc drawContext) {
 
 		w := labelW + textRectW + hGap*2
 		h := labelH + vGap*2
+		p.width, p.height = w/p.size, h/p.size
 		drawRoundRect(dc, x, y, w, h, stmBackground, stmBackgroundPen)
 		if p.label != "" {
 			labelRender.Draw(dc.Image, int(x+hGap), int(y+vGap), color.Black, 0)
@@ -306,60 +296,3 @@ func getRoundRect(dc drawContext, x, y, w, h float64, clr, clrPen Color) (image.
 func (p *Monitor) hit(hc hitContext) (hr hitResult, ok bool) {
 	return
 }
-
-// -------------------------------------------------------------------------------------
-// IWidget
-func (pself *Monitor) GetName() WidgetName {
-	return pself.name
-}
-
-func (pself *Monitor) Visible() bool {
-	return pself.visible
-}
-func (pself *Monitor) Show() {
-	pself.visible = true
-}
-func (pself *Monitor) Hide() {
-	pself.visible = false
-}
-func (pself *Monitor) Xpos() float64 {
-	return pself.x
-}
-func (pself *Monitor) Ypos() float64 {
-	return pself.y
-}
-func (pself *Monitor) SetXpos(x float64) {
-	pself.x = x
-}
-func (pself *Monitor) SetYpos(y float64) {
-	pself.y = y
-}
-func (pself *Monitor) SetXYpos(x float64, y float64) {
-	pself.x, pself.y = x, y
-}
-func (pself *Monitor) ChangeXpos(dx float64) {
-	pself.x += dx
-}
-func (pself *Monitor) ChangeYpos(dy float64) {
-	pself.y += dy
-}
-func (pself *Monitor) ChangeXYpos(dx float64, dy float64) {
-	pself.x += dx
-	pself.y += dy
-}
-
-func (pself *Monitor) Size() float64 {
-	return pself.size
-}
-func (pself *Monitor) SetSize(size float64) {
-	pself.size = size
-	pself.updateSize()
-}
-func (pself *Monitor) ChangeSize(delta float64) {
-	pself.size += delta
-	pself.updateSize()
-}
-
-func (pself *Monitor) updateSize() {
-
-}
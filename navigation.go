@@ -0,0 +1,258 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"log"
+	"math"
+
+	"github.com/goplus/spx/internal/nav"
+	"github.com/goplus/spx/internal/tiled"
+)
+
+// -------------------------------------------------------------------------------------
+
+// PathPoint is a position on the stage.
+type PathPoint struct {
+	X, Y float64
+}
+
+// Path is a list of positions to go through, see SpriteImpl.GlideAlong.
+type Path []PathPoint
+
+// length returns the length of the path.
+func (p Path) length() float64 {
+	l := 0.0
+	for i := 1; i < len(p); i++ {
+		l += math.Hypot(p[i].X-p[i-1].X, p[i].Y-p[i-1].Y)
+	}
+	return l
+}
+
+// pointAt returns the position at the distance d along the path, and the
+// heading of the segment it is on.
+func (p Path) pointAt(d float64) (x, y, heading float64) {
+	var a, b PathPoint
+	for i := 1; i < len(p); i++ {
+		a, b = p[i-1], p[i]
+		l := math.Hypot(b.X-a.X, b.Y-a.Y)
+		if l == 0 {
+			continue
+		}
+		heading = 90 - math.Atan2(b.Y-a.Y, b.X-a.X)*180/math.Pi
+		if d <= l || i == len(p)-1 {
+			t := math.Max(0, math.Min(d/l, 1))
+			return a.X + (b.X-a.X)*t, a.Y + (b.Y-a.Y)*t, heading
+		}
+		d -= l
+	}
+	return b.X, b.Y, heading
+}
+
+// NavGrid is a grid of walkable cells over the stage, to find paths.
+type NavGrid struct {
+	grid         *nav.Grid
+	left, top    float64 // top-left corner, in stage coordinates
+	cellW, cellH float64
+	opts         nav.Options
+}
+
+// newNavGrid creates a cols x rows grid, with walkable tested at the center
+// of each cell.
+func newNavGrid(left, top, cellW, cellH float64, cols, rows int, walkable func(x, y float64) bool) *NavGrid {
+	p := &NavGrid{left: left, top: top, cellW: cellW, cellH: cellH}
+	p.grid = nav.NewGrid(cols, rows, func(col, row int) bool {
+		pt := p.cellCenter(nav.Point{X: col, Y: row})
+		return walkable(pt.X, pt.Y)
+	})
+	return p
+}
+
+// NewNavGrid creates a navigation grid covering the world, with cells of
+// cellSize. walkable(x, y) reports if the cell centered at (x, y) is walkable.
+func (p *Game) NewNavGrid(cellSize float64, walkable func(x, y float64) bool) *NavGrid {
+	if cellSize <= 0 {
+		log.Println("NewNavGrid: invalid cell size -", cellSize)
+		return nil
+	}
+	worldW, worldH := p.worldSize_()
+	cols := int(math.Ceil(float64(worldW) / cellSize))
+	rows := int(math.Ceil(float64(worldH) / cellSize))
+	return newNavGrid(-float64(worldW)/2, float64(worldH)/2, cellSize, cellSize, cols, rows, walkable)
+}
+
+// NavGridFromBackdrop creates a navigation grid covering the world, where the
+// cells which centers are of the wall color in the current backdrop are
+// blocked.
+func (p *Game) NavGridFromBackdrop(cellSize float64, wall Color) *NavGrid {
+	wr, wg, wb, wa := wall.RGBA()
+	return p.NewNavGrid(cellSize, func(x, y float64) bool {
+		r, g, b, a := p.backdropColorAt(x, y).RGBA()
+		return r != wr || g != wg || b != wb || a != wa
+	})
+}
+
+// NavGrid__0 creates a navigation grid from the collision layer of the
+// tilemap, where solid tiles are blocked.
+func (p *Tilemap) NavGrid__0() *NavGrid {
+	return p.navGrid(p.collision)
+}
+
+// NavGrid__1 creates a navigation grid from a layer of the tilemap, where
+// non-empty tiles are blocked.
+func (p *Tilemap) NavGrid__1(layer string) *NavGrid {
+	return p.navGrid(p.layer(layer))
+}
+
+func (p *Tilemap) navGrid(layer *tiled.Layer) *NavGrid {
+	w, h := p.size()
+	tw, th := float64(p.m.TileWidth), float64(p.m.TileHeight)
+	return newNavGrid(p.x-w/2, p.y+h/2, tw, th, p.m.Width, p.m.Height, func(x, y float64) bool {
+		col, row := p.cellAt(x, y)
+		return tileAt(layer, col, row) == 0
+	})
+}
+
+// SetDiagonal allows diagonal moves between cells, but never between two
+// blocked cells.
+func (p *NavGrid) SetDiagonal(on bool) {
+	p.opts.Diagonal = on
+}
+
+// SetJPS enables jump point search, which is faster than A* on large open
+// grids. It only works with diagonal moves.
+func (p *NavGrid) SetJPS(on bool) {
+	p.opts.JPS = on
+}
+
+func (p *NavGrid) Walkable(x, y float64) bool {
+	pt := p.cellAt(x, y)
+	return p.grid.Walkable(pt.X, pt.Y)
+}
+
+func (p *NavGrid) SetWalkable(x, y float64, walkable bool) {
+	pt := p.cellAt(x, y)
+	p.grid.SetWalkable(pt.X, pt.Y, walkable)
+}
+
+func (p *NavGrid) cellAt(x, y float64) nav.Point {
+	return nav.Point{
+		X: int(math.Floor((x - p.left) / p.cellW)),
+		Y: int(math.Floor((p.top - y) / p.cellH)),
+	}
+}
+
+func (p *NavGrid) cellCenter(pt nav.Point) PathPoint {
+	return PathPoint{
+		X: p.left + (float64(pt.X)+0.5)*p.cellW,
+		Y: p.top - (float64(pt.Y)+0.5)*p.cellH,
+	}
+}
+
+// FindPath returns a path from (x0, y0) to (x1, y1) through the centers of
+// walkable cells, or nil if there is no path. The path only keeps the cells
+// where it turns, and ends at (x1, y1).
+func (p *NavGrid) FindPath(x0, y0, x1, y1 float64) Path {
+	cells := p.grid.FindPath(p.cellAt(x0, y0), p.cellAt(x1, y1), p.opts)
+	if cells == nil {
+		return nil
+	}
+	path := make(Path, 0, len(cells))
+	for i := 1; i < len(cells)-1; i++ {
+		prev, curr, next := cells[i-1], cells[i], cells[i+1]
+		if curr.X-prev.X != next.X-curr.X || curr.Y-prev.Y != next.Y-curr.Y {
+			path = append(path, p.cellCenter(curr))
+		}
+	}
+	return append(path, PathPoint{x1, y1})
+}
+
+// -------------------------------------------------------------------------------------
+
+// SetNavGrid sets the navigation grid used by SpriteImpl.FindPath.
+func (p *Game) SetNavGrid(grid *NavGrid) {
+	p.navGrid = grid
+}
+
+func (p *Game) NavGrid() *NavGrid {
+	return p.navGrid
+}
+
+// FindPath func:
+//
+//	FindPath(x, y)
+//	FindPath(sprite)
+//	FindPath(spx.Mouse)
+func (p *SpriteImpl) findPath(obj interface{}) Path {
+	x, y := p.g.objectPos(obj)
+	return p.FindPath__0(x, y)
+}
+
+// FindPath__0 returns a path from the sprite to (x, y) on the navigation grid
+// of the game, or nil if there is no path.
+func (p *SpriteImpl) FindPath__0(x, y float64) Path {
+	if p.g.navGrid == nil {
+		log.Println("FindPath: no navigation grid, see Game.SetNavGrid")
+		return nil
+	}
+	return p.g.navGrid.FindPath(p.x, p.y, x, y)
+}
+
+func (p *SpriteImpl) FindPath__1(sprite Sprite) Path {
+	return p.findPath(sprite)
+}
+
+func (p *SpriteImpl) FindPath__2(sprite SpriteName) Path {
+	return p.findPath(sprite)
+}
+
+func (p *SpriteImpl) FindPath__3(obj specialObj) Path {
+	return p.findPath(obj)
+}
+
+// GlideAlong moves the sprite through the positions of path, at speed in
+// steps per second. Like Step, it plays the step animation once along the
+// whole path if the sprite has one, turning to each position, else it glides.
+func (p *SpriteImpl) GlideAlong(path Path, speed float64) {
+	if debugInstr {
+		log.Println("GlideAlong", p.name, len(path), speed)
+	}
+	if speed <= 0 {
+		log.Println("GlideAlong: invalid speed -", speed)
+		return
+	}
+	if len(path) == 0 {
+		return
+	}
+	animName := p.getStateAnimName(StateStep)
+	if ani, ok := p.animations[animName]; ok {
+		path = append(Path{{p.x, p.y}}, path...)
+		if dist := path.length(); dist > 0 {
+			p.goStepAnimate(animName, ani, dist, dist/speed, path)
+			last := path[len(path)-1]
+			p.doMoveTo(last.X, last.Y) // fix rounding errors of the animation
+		}
+		return
+	}
+	for _, pt := range path {
+		if dist := math.Hypot(pt.X-p.x, pt.Y-p.y); dist > 0 {
+			p.Glide__0(pt.X, pt.Y, dist/speed)
+		}
+	}
+}
+
+// -------------------------------------------------------------------------------------
@@ -0,0 +1,25 @@
+package spx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPathPointAt(t *testing.T) {
+	path := Path{{0, 0}, {0, 0}, {30, 0}, {30, 40}}
+	if l := path.length(); l != 70 {
+		t.Fatal("length:", l)
+	}
+	for _, tc := range []struct{ d, x, y, heading float64 }{
+		{0, 0, 0, 90},
+		{15, 15, 0, 90},
+		{30, 30, 0, 90},
+		{50, 30, 20, 0},
+		{80, 30, 40, 0},
+	} {
+		x, y, heading := path.pointAt(tc.d)
+		if math.Abs(x-tc.x) > 1e-9 || math.Abs(y-tc.y) > 1e-9 || math.Abs(heading-tc.heading) > 1e-9 {
+			t.Fatal("pointAt:", tc.d, x, y, heading)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"math"
+
+	"github.com/goplus/spx/internal/math32"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// -------------------------------------------------------------------------------------
+
+// mapLayer is a background layer drawn on top of the backdrop and behind all
+// shapes. It moves with the parallax factor relative to the main camera: 1
+// moves with the world, 0 stays still on the screen.
+type mapLayer struct {
+	img      delayloadImage
+	backdrop string // only drawn with this backdrop, or with all if empty
+	parallax math32.Vector2
+	offset   math32.Vector2
+	speed    math32.Vector2 // pixels per second
+	scroll   math32.Vector2
+	repeatX  bool
+	repeatY  bool
+}
+
+func newMapLayer(conf *mapLayerConfig) *mapLayer {
+	p := &mapLayer{
+		img:      delayloadImage{loader: imageLoaderByPath(conf.Path)},
+		backdrop: conf.Backdrop,
+		parallax: math32.Vector2{X: 1, Y: 1},
+		offset:   conf.Offset,
+		speed:    conf.Speed,
+	}
+	if conf.Parallax != nil {
+		p.parallax = *conf.Parallax
+	}
+	switch conf.Repeat {
+	case "x":
+		p.repeatX = true
+	case "y":
+		p.repeatY = true
+	case "xy":
+		p.repeatX, p.repeatY = true, true
+	}
+	return p
+}
+
+func (p *mapLayer) update(tps float64) {
+	p.scroll.X += p.speed.X / tps
+	p.scroll.Y += p.speed.Y / tps
+}
+
+// tileRange returns the tile indexes [from, to] needed to cover [viewMin, viewMax]
+// with tiles of the specified size, where tile 0 starts at pos.
+func tileRange(pos, size, viewMin, viewMax float64, repeat bool) (from, to int) {
+	if !repeat || size <= 0 {
+		return 0, 0
+	}
+	from = int(math.Floor((viewMin - pos) / size))
+	to = int(math.Floor((viewMax - pos) / size))
+	return
+}
+
+func (p *mapLayer) draw(dc drawContext, g *Game) {
+	p.img.ensure(g.fs)
+	img := p.img.cache.Ebiten()
+	imgW, imgH := float64(img.Bounds().Dx()), float64(img.Bounds().Dy())
+	worldW, worldH := g.worldSize_()
+
+	cam := g.Camera.freecamera.GetPos()
+	x := p.offset.X + p.scroll.X + cam.X*(1-p.parallax.X)
+	y := p.offset.Y + p.scroll.Y + cam.Y*(1-p.parallax.Y)
+	left := float64(worldW)/2 + x - imgW/2 // convert to world image pixels
+	top := float64(worldH)/2 - y - imgH/2
+
+	minX, minY, maxX, maxY := g.viewRect()
+	fromX, toX := tileRange(left, imgW, minX, maxX, p.repeatX)
+	fromY, toY := tileRange(top, imgH, minY, maxY, p.repeatY)
+	for i := fromX; i <= toX; i++ {
+		for j := fromY; j <= toY; j++ {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(left+imgW*float64(i), top+imgH*float64(j))
+			dc.DrawImage(img, op)
+		}
+	}
+}
+
+// -------------------------------------------------------------------------------------
+
+func (p *Game) initMapLayers(confs []*mapLayerConfig) {
+	p.mapLayers = make([]*mapLayer, 0, len(confs))
+	for _, conf := range confs {
+		p.mapLayers = append(p.mapLayers, newMapLayer(conf))
+	}
+}
+
+func (p *Game) updateMapLayers() {
+	tps := p.currentTPS()
+	for _, layer := range p.mapLayers {
+		layer.update(tps)
+	}
+}
+
+func (p *Game) drawMapLayers(dc drawContext) {
+	if len(p.mapLayers) == 0 {
+		return
+	}
+	backdrop := p.getCostumeName()
+	for _, layer := range p.mapLayers {
+		if layer.backdrop == "" || layer.backdrop == backdrop {
+			layer.draw(dc, p)
+		}
+	}
+}
+
+// -------------------------------------------------------------------------------------
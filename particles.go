@@ -0,0 +1,367 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"image/color"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/goplus/spx/internal/particle"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// -------------------------------------------------------------------------------------
+
+const (
+	defaultMaxParticles = 1000
+	particleSquareSize  = 4     // size of particles without a texture
+	maxParticlesPerDraw = 16384 // vertices are indexed by uint16
+)
+
+// ParticleOpts are the options of Game.NewParticles.
+type ParticleOpts struct {
+	Name    string
+	Sprite  SpriteName        // the texture is a costume of this sprite, or a square if empty
+	Costume SpriteCostumeName // the current costume of Sprite if empty
+	X, Y    float64
+
+	Rate     float64 // particles emitted per second
+	Burst    int     // particles emitted when the emitter starts
+	Duration float64 // seconds emitting at Rate, 0 for ever
+	Max      int     // max live particles, 1000 by default
+
+	Lifetime, LifetimeVariance float64 // in seconds, 1 by default
+	Speed, SpeedVariance       float64 // in pixels per second
+
+	// Direction is the heading of particles, 0 is up and 90 is right. They
+	// are emitted in Direction ± Spread.
+	Direction, Spread float64
+
+	GravityX, GravityY float64 // in pixels per second squared
+
+	// Size, Alpha and Color change over the life of a particle, from its
+	// birth to its death. They are values evenly spaced over the life.
+	Size  []float64 // 1 by default
+	Alpha []float64 // 1 by default
+	Color []Color   // tints the texture, white by default
+}
+
+// Particles is an emitter of particles, for effects like explosions, smoke
+// and sparkles. Particles are not sprites: they don't collide or receive
+// events, and all of them are drawn at once.
+//
+//	{
+//	  "type": "particles",
+//	  "name": "sparks",
+//	  "sprite": "Spark",
+//	  "costume": "dot",
+//	  "x": 0,
+//	  "y": 0,
+//	  "rate": 30,
+//	  "burst": 10,
+//	  "lifetime": 1,
+//	  "lifetimeVariance": 0.3,
+//	  "speed": 120,
+//	  "spread": 180,
+//	  "gravityY": -300,
+//	  "size": [1, 0.2],
+//	  "alpha": [1, 0],
+//	  "color": ["#ffff00", "#ff0000"],
+//	  "autoStart": true
+//	}
+type Particles struct {
+	game    *Game
+	name    string
+	em      *particle.Emitter
+	emM     sync.Mutex // scripts change the emitter while Update advances it
+	sprite  SpriteName
+	costume SpriteCostumeName
+	visible bool
+
+	tex      *ebiten.Image // resolved on the first draw
+	texW     float64
+	texH     float64
+	vertices []ebiten.Vertex
+	indices  []uint16
+}
+
+func newParticles(g *Game, opts *ParticleOpts) *Particles {
+	conf := particle.Config{
+		Rate: opts.Rate, Burst: opts.Burst, Duration: opts.Duration, Max: opts.Max,
+		Lifetime: opts.Lifetime, LifetimeVariance: opts.LifetimeVariance,
+		Speed: opts.Speed, SpeedVariance: opts.SpeedVariance,
+		Direction: opts.Direction, Spread: opts.Spread,
+		GravityX: opts.GravityX, GravityY: opts.GravityY,
+		Size: opts.Size, Alpha: opts.Alpha, Color: opts.Color,
+	}
+	if conf.Max <= 0 {
+		conf.Max = defaultMaxParticles
+	}
+	if conf.Lifetime <= 0 {
+		conf.Lifetime = 1
+	}
+	em := particle.New(conf, time.Now().UnixNano())
+	em.X, em.Y = opts.X, opts.Y
+	return &Particles{
+		game: g, name: opts.Name, em: em,
+		sprite: opts.Sprite, costume: opts.Costume, visible: true,
+	}
+}
+
+func newParticlesFrom(g *Game, v specsp) *Particles {
+	floats := func(key string) []float64 {
+		vals, _ := v[key].([]interface{})
+		ret := make([]float64, 0, len(vals))
+		for _, val := range vals {
+			if f, ok := val.(float64); ok {
+				ret = append(ret, f)
+			}
+		}
+		return ret
+	}
+	number := func(key string) float64 {
+		f, _ := getSpcspVal(v, key, 0.0).(float64)
+		return f
+	}
+	opts := &ParticleOpts{
+		Rate: number("rate"), Burst: int(number("burst")), Duration: number("duration"), Max: int(number("max")),
+		X: number("x"), Y: number("y"),
+		Lifetime: number("lifetime"), LifetimeVariance: number("lifetimeVariance"),
+		Speed: number("speed"), SpeedVariance: number("speedVariance"),
+		Direction: number("direction"), Spread: number("spread"),
+		GravityX: number("gravityX"), GravityY: number("gravityY"),
+		Size: floats("size"), Alpha: floats("alpha"),
+	}
+	opts.Name, _ = getSpcspVal(v, "name", "").(string)
+	opts.Sprite, _ = getSpcspVal(v, "sprite", "").(string)
+	opts.Costume, _ = getSpcspVal(v, "costume", "").(string)
+	colors, _ := v["color"].([]interface{})
+	for _, val := range colors {
+		c, err := parseColor(val)
+		if err != nil {
+			panic(err)
+		}
+		opts.Color = append(opts.Color, c)
+	}
+	p := newParticles(g, opts)
+	if visible, ok := v["visible"].(bool); ok {
+		p.visible = visible
+	}
+	if autoStart, _ := getSpcspVal(v, "autoStart", true).(bool); autoStart {
+		p.em.Start()
+	}
+	return p
+}
+
+func (p *Particles) Name() string {
+	return p.name
+}
+
+func (p *Particles) Show() {
+	p.visible = true
+}
+
+func (p *Particles) Hide() {
+	p.visible = false
+}
+
+func (p *Particles) Visible() bool {
+	return p.visible
+}
+
+func (p *Particles) Xpos() float64 {
+	p.emM.Lock()
+	defer p.emM.Unlock()
+	return p.em.X
+}
+
+func (p *Particles) Ypos() float64 {
+	p.emM.Lock()
+	defer p.emM.Unlock()
+	return p.em.Y
+}
+
+// SetXYpos moves the emitter. Particles already emitted don't move with it.
+func (p *Particles) SetXYpos(x, y float64) {
+	p.emM.Lock()
+	defer p.emM.Unlock()
+	p.em.X, p.em.Y = x, y
+}
+
+// Start emits a burst of particles and starts emitting at the rate.
+func (p *Particles) Start() {
+	p.emM.Lock()
+	defer p.emM.Unlock()
+	p.em.Start()
+}
+
+// Stop stops emitting. Particles already emitted live on.
+func (p *Particles) Stop() {
+	p.emM.Lock()
+	defer p.emM.Unlock()
+	p.em.Stop()
+}
+
+// Emitting reports if the emitter emits particles at its rate.
+func (p *Particles) Emitting() bool {
+	p.emM.Lock()
+	defer p.emM.Unlock()
+	return p.em.Emitting()
+}
+
+// Burst emits n particles at once.
+func (p *Particles) Burst(n int) {
+	p.emM.Lock()
+	defer p.emM.Unlock()
+	p.em.Emit(n)
+}
+
+// Count returns how many particles are alive.
+func (p *Particles) Count() int {
+	p.emM.Lock()
+	defer p.emM.Unlock()
+	return len(p.em.Particles)
+}
+
+// Destroy removes the emitter and its particles from the stage.
+func (p *Particles) Destroy() {
+	p.game.removeShape(p)
+}
+
+func (p *Particles) texture() *ebiten.Image {
+	if p.tex != nil {
+		return p.tex
+	}
+	if p.sprite != "" {
+		if sp, ok := p.game.sprs[p.sprite]; ok {
+			src := spriteOf(sp)
+			idx := src.costumeIndex_
+			if p.costume != "" {
+				if idx = src.findCostume(p.costume); idx < 0 {
+					log.Println("Particles: costume not found -", p.costume)
+					idx = src.costumeIndex_
+				}
+			}
+			c := src.costumes[idx]
+			img, _, _ := c.needImage(p.game.fs)
+			b := img.Bounds()
+			p.tex = img.Ebiten()
+			p.texW = float64(b.Dx()) / float64(c.bitmapResolution)
+			p.texH = float64(b.Dy()) / float64(c.bitmapResolution)
+			return p.tex
+		}
+		log.Println("Particles: sprite not found -", p.sprite)
+	}
+	p.tex = ebiten.NewImage(particleSquareSize, particleSquareSize)
+	p.tex.Fill(color.White)
+	p.texW, p.texH = particleSquareSize, particleSquareSize
+	return p.tex
+}
+
+func (p *Particles) update(tps float64) {
+	p.emM.Lock()
+	defer p.emM.Unlock()
+	p.em.Update(1 / tps)
+}
+
+// draw draws all particles by one DrawTriangles call, unless they are too
+// many for the indices of one call.
+func (p *Particles) draw(dc drawContext) {
+	p.emM.Lock()
+	defer p.emM.Unlock()
+	pts := p.em.Particles
+	if !p.visible || len(pts) == 0 {
+		return
+	}
+	img := p.texture()
+	b := img.Bounds()
+	sx0, sy0, sx1, sy1 := float32(b.Min.X), float32(b.Min.Y), float32(b.Max.X), float32(b.Max.Y)
+	worldW, worldH := p.game.worldSize_()
+	ox, oy := float64(worldW)/2, float64(worldH)/2
+	op := new(ebiten.DrawTrianglesOptions)
+	op.Filter = ebiten.FilterLinear
+	conf := &p.em.Config
+	for len(pts) > 0 {
+		n := len(pts)
+		if n > maxParticlesPerDraw {
+			n = maxParticlesPerDraw
+		}
+		vs, is := p.vertices[:0], p.indices[:0]
+		for i := range pts[:n] {
+			pt := &pts[i]
+			life := pt.Life()
+			size := conf.Size.At(life)
+			hw, hh := float32(p.texW*size/2), float32(p.texH*size/2)
+			r, g, bl, a := conf.Color.At(life)
+			cr, cg, cb, ca := float32(r), float32(g), float32(bl), float32(a*conf.Alpha.At(life))
+			x, y := float32(ox+pt.X), float32(oy-pt.Y)
+			vs = append(vs,
+				ebiten.Vertex{DstX: x - hw, DstY: y - hh, SrcX: sx0, SrcY: sy0, ColorR: cr, ColorG: cg, ColorB: cb, ColorA: ca},
+				ebiten.Vertex{DstX: x + hw, DstY: y - hh, SrcX: sx1, SrcY: sy0, ColorR: cr, ColorG: cg, ColorB: cb, ColorA: ca},
+				ebiten.Vertex{DstX: x + hw, DstY: y + hh, SrcX: sx1, SrcY: sy1, ColorR: cr, ColorG: cg, ColorB: cb, ColorA: ca},
+				ebiten.Vertex{DstX: x - hw, DstY: y + hh, SrcX: sx0, SrcY: sy1, ColorR: cr, ColorG: cg, ColorB: cb, ColorA: ca},
+			)
+			base := uint16(i * 4)
+			is = append(is, base, base+1, base+2, base+2, base+3, base)
+		}
+		dc.DrawTriangles(vs, is, img, op)
+		p.vertices, p.indices = vs, is
+		pts = pts[n:]
+	}
+}
+
+func (p *Particles) hit(hc hitContext) (hitResult, bool) {
+	return hitResult{}, false
+}
+
+// -------------------------------------------------------------------------------------
+
+// NewParticles creates an emitter of particles on the stage, and starts it.
+func (p *Game) NewParticles(opts *ParticleOpts) *Particles {
+	if opts == nil {
+		opts = &ParticleOpts{}
+	}
+	ps := newParticles(p, opts)
+	p.addShape(ps)
+	ps.Start()
+	return ps
+}
+
+// Particles returns the emitter of particles with the specified name, or nil
+// if not found.
+func (p *Game) Particles(name string) *Particles {
+	for _, item := range p.items {
+		if ps, ok := item.(*Particles); ok && ps.name == name {
+			return ps
+		}
+	}
+	log.Println("Particles not found:", name)
+	return nil
+}
+
+func (p *Game) updateParticles() {
+	tps := p.currentTPS()
+	for _, item := range p.items {
+		if ps, ok := item.(*Particles); ok {
+			ps.update(tps)
+		}
+	}
+}
+
+// -------------------------------------------------------------------------------------
@@ -17,74 +17,196 @@
 package spx
 
 import (
-	"fmt"
+	"image"
 	"image/color"
+	"math"
+	"sync"
 
-	"github.com/goplus/spx/internal/gdi"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// -------------------------------------------------------------------------------------
+
+// PenColorParam is a color parameter of a pen, from 0 to 100, as the ones of
+// Scratch 3.
+type PenColorParam int
+
+const (
+	ColorParam PenColorParam = iota // hue
+	SaturationParam
+	BrightnessParam
+	TransparencyParam
+
+	numPenColorParams
+)
+
+var penColorParamNames = []string{
+	ColorParam:        "Color",
+	SaturationParam:   "Saturation",
+	BrightnessParam:   "Brightness",
+	TransparencyParam: "Transparency",
+}
+
+func (param PenColorParam) String() string {
+	return penColorParamNames[param]
+}
+
+// defaultPenParams makes the default pen blue and opaque.
+var defaultPenParams = [numPenColorParams]float64{
+	ColorParam:      66.66,
+	SaturationParam: 100,
+	BrightnessParam: 100,
+}
+
+// PenCap is the shape of the ends of pen lines.
+type PenCap int
+
+const (
+	PenCapRound PenCap = iota
+	PenCapButt
+	PenCapSquare
+)
+
+var (
+	whiteImage = ebiten.NewImage(3, 3)
 
-	spxfs "github.com/goplus/spx/fs"
+	// whiteSubImage is the source of solid triangles. Its inner pixel isn't
+	// blended with transparent pixels around it by linear filtering.
+	whiteSubImage = whiteImage.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
 )
 
+func init() {
+	whiteImage.Fill(color.White)
+}
+
+// solidVertex returns a vertex of a solid triangle drawn from whiteSubImage.
+func solidVertex(x, y float32, clr color.RGBA, alpha float32) ebiten.Vertex {
+	return ebiten.Vertex{
+		DstX: x, DstY: y, SrcX: 1, SrcY: 1,
+		ColorR: float32(clr.R) / 0xff, ColorG: float32(clr.G) / 0xff, ColorB: float32(clr.B) / 0xff,
+		ColorA: float32(clr.A) / 0xff * alpha,
+	}
+}
+
 // -------------------------------------------------------------------------------------
 
+// penLine is a line drawn by a pen, in world image pixels.
 type penLine struct {
-	x1, y1 int
-	x2, y2 int
+	x1, y1 float64
+	x2, y2 float64
 	clr    color.RGBA
-	width  int
+	width  float64
+	cap    PenCap
+	dash   float64 // length of dashes, 0 for a solid line
+	gap    float64 // length of gaps between dashes
+	offset float64 // where the line starts in the dash pattern
 }
 
-func (p *penLine) draw(dc gdi.Canvas) {
-	clr := p.clr
-	style := fmt.Sprintf("stroke-linecap:round;stroke-width:%d;stroke:rgb(%d,%d,%d)", p.width, clr.R, clr.G, clr.B)
-	if clr.A != 0xff {
-		style = fmt.Sprintf("%s;stroke-opacity:%.2f", style, float64(clr.A)/0xff)
+func (p *penLine) draw(dst *ebiten.Image) {
+	if p.clr.A == 0 || p.width <= 0 {
+		return
+	}
+	var path vector.Path
+	dx, dy := p.x2-p.x1, p.y2-p.y1
+	if l := math.Hypot(dx, dy); p.dash > 0 && l > 0 {
+		period := p.dash + p.gap
+		for s := -math.Mod(p.offset, period); s < l; s += period {
+			from, to := math.Max(s, 0), math.Min(s+p.dash, l)
+			if to > from {
+				path.MoveTo(float32(p.x1+dx*from/l), float32(p.y1+dy*from/l))
+				path.LineTo(float32(p.x1+dx*to/l), float32(p.y1+dy*to/l))
+			}
+		}
+	} else {
+		path.MoveTo(float32(p.x1), float32(p.y1))
+		path.LineTo(float32(p.x2), float32(p.y2))
+	}
+	op := &vector.StrokeOptions{Width: float32(p.width), LineJoin: vector.LineJoinRound}
+	switch p.cap {
+	case PenCapRound:
+		op.LineCap = vector.LineCapRound
+	case PenCapSquare:
+		op.LineCap = vector.LineCapSquare
+	}
+	vs, is := path.AppendVerticesAndIndicesForStroke(nil, nil, op)
+	for i := range vs {
+		vs[i] = solidVertex(vs[i].DstX, vs[i].DstY, p.clr, 1)
 	}
-	dc.Line(p.x1, p.y1, p.x2, p.y2, style)
+	dst.DrawTriangles(vs, is, whiteSubImage, &ebiten.DrawTrianglesOptions{AntiAlias: true})
+}
+
+// penStamp is a sprite stamped by Stamp, as it was when stamped.
+type penStamp struct {
+	draw func(dc drawContext)
 }
 
 // -------------------------------------------------------------------------------------
 
+// turtleCanvas is the layer which pens draw on. It is sized to the world and
+// drawn over the backdrop, so the camera pans and zooms it with the world.
+// Lines and stamps are drawn onto it once, in the next draw. Scripts queue
+// them under mutex, which draw takes them from.
 type turtleCanvas struct {
-	objs []interface{}
+	img *ebiten.Image
+
+	mutex   sync.Mutex
+	pending []interface{}
+	erase   bool // clear img before drawing pending
 }
 
 func (p *turtleCanvas) eraseAll() {
-	p.objs = nil
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.pending, p.erase = nil, true
 }
 
 func (p *turtleCanvas) penLine(obj *penLine) {
-	p.objs = append(p.objs, obj)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.pending = append(p.pending, obj)
 }
 
-func (p *turtleCanvas) stampCostume(obj *spriteDrawInfo) {
-	p.objs = append(p.objs, obj)
+func (p *turtleCanvas) stampCostume(obj *penStamp) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.pending = append(p.pending, obj)
 }
 
-func (p turtleCanvas) draw(dc drawContext, fs spxfs.Dir) {
-	if p.objs == nil {
-		return
-	}
+func (p *turtleCanvas) draw(dc drawContext) {
+	p.mutex.Lock()
+	pending, erase := p.pending, p.erase
+	p.pending, p.erase = nil, false
+	p.mutex.Unlock()
 
-	var canvas gdi.Canvas
-	for _, obj := range p.objs {
+	b := dc.Bounds()
+	worldW, worldH := b.Dx(), b.Dy()
+	if p.img == nil {
+		if pending == nil {
+			return
+		}
+		p.img = ebiten.NewImage(worldW, worldH)
+	} else if old := p.img.Bounds(); old.Dx() != worldW || old.Dy() != worldH {
+		// the world is resized, keep the drawing centered
+		img := ebiten.NewImage(worldW, worldH)
+		op := new(ebiten.DrawImageOptions)
+		op.GeoM.Translate(float64(worldW-old.Dx())/2, float64(worldH-old.Dy())/2)
+		img.DrawImage(p.img, op)
+		p.img.Dispose()
+		p.img = img
+	}
+	if erase {
+		p.img.Clear()
+	}
+	for _, obj := range pending {
 		switch o := obj.(type) {
 		case *penLine:
-			if canvas.Target == nil {
-				canvas = gdi.Start(dc.Image)
-			}
-			o.draw(canvas)
-		case *spriteDrawInfo:
-			if canvas.Target != nil {
-				canvas.End()
-				canvas.Target = nil
-			}
-			o.drawOn(dc, fs)
+			o.draw(p.img)
+		case *penStamp:
+			o.draw(drawContext{Image: p.img})
 		}
 	}
-	if canvas.Target != nil {
-		canvas.End()
-	}
+	dc.DrawImage(p.img, nil)
 }
 
 // -------------------------------------------------------------------------------------
@@ -0,0 +1,36 @@
+package spx
+
+import (
+	"math"
+	"testing"
+
+	"github.com/goplus/spx/internal/gdi/hsv"
+)
+
+func TestPenColorParamThenHue(t *testing.T) {
+	p := &SpriteImpl{penParams: defaultPenParams, penShade: 50}
+	p.SetPenColorParam(SaturationParam, 40)
+	p.SetPenColorParam(BrightnessParam, 70)
+	p.ChangePenHue(20)
+	p.ChangePenHue(20)
+
+	want := [numPenColorParams]float64{ColorParam: 86.66, SaturationParam: 40, BrightnessParam: 70}
+	for i, v := range p.penParams {
+		if math.Abs(v-want[i]) > 1e-9 {
+			t.Fatal("param", PenColorParam(i), v, "expected:", want[i])
+		}
+	}
+	r, g, b := hsv.ToRGBf(0.8666, 0.4, 0.7)
+	if c := p.penColor; c.R != uint8(math.Round(r*0xff)) || c.G != uint8(math.Round(g*0xff)) ||
+		c.B != uint8(math.Round(b*0xff)) || c.A != 0xff {
+		t.Fatal("penColor:", c)
+	}
+	if math.Abs(p.penShade-32) > 1e-9 { // 70% brightness is mixed with black
+		t.Fatal("penShade:", p.penShade)
+	}
+
+	p.SetPenShade(50)
+	if math.Abs(p.penParams[ColorParam]-want[ColorParam]) > 1e-9 || p.penParams[SaturationParam] != 100 || p.penParams[BrightnessParam] != 100 {
+		t.Fatal("SetPenShade:", p.penParams)
+	}
+}
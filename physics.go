@@ -0,0 +1,345 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"log"
+	"math"
+
+	"github.com/goplus/spx/internal/spatial"
+)
+
+// -------------------------------------------------------------------------------------
+
+type BodyType int
+
+const (
+	NoBody        BodyType = iota // not simulated by the physics world
+	StaticBody                    // never moves
+	DynamicBody                   // moved by gravity, forces and contacts
+	KinematicBody                 // moved by its velocity only, pushes dynamic bodies
+)
+
+func toBodyType(typ string) BodyType {
+	switch typ {
+	case "static":
+		return StaticBody
+	case "dynamic":
+		return DynamicBody
+	case "kinematic":
+		return KinematicBody
+	}
+	return NoBody
+}
+
+const (
+	physicsStep       = 1.0 / 60 // fixed timestep, in seconds
+	physicsMaxSteps   = 4        // max steps per update, the rest is dropped
+	physicsIterations = 8        // velocity solver iterations per step
+	physicsSlop       = 0.5      // allowed penetration, in pixels
+	physicsCorrection = 0.8      // ratio of penetration resolved per step
+	physicsBounceMin  = 30.0     // min approach speed to bounce, in pixels/s
+	defaultFriction   = 0.3
+	defaultGravity    = -980.0
+)
+
+type rigidBody struct {
+	typ          BodyType
+	mass         float64
+	friction     float64
+	restitution  float64
+	gravityScale float64
+	vx, vy       float64 // velocity, in pixels/s
+	fx, fy       float64 // force accumulated until the next step
+}
+
+func (p *rigidBody) init(conf *bodyConfig) {
+	*p = rigidBody{mass: 1, friction: defaultFriction, gravityScale: 1}
+	if conf == nil {
+		return
+	}
+	p.typ = toBodyType(conf.Type)
+	if conf.Mass > 0 {
+		p.mass = conf.Mass
+	}
+	if conf.Friction != nil {
+		p.friction = *conf.Friction
+	}
+	if conf.GravityScale != nil {
+		p.gravityScale = *conf.GravityScale
+	}
+	p.restitution = conf.Restitution
+	p.vx, p.vy = conf.Velocity.X, conf.Velocity.Y
+}
+
+func (p *rigidBody) invMass() float64 {
+	if p.typ != DynamicBody {
+		return 0
+	}
+	return 1 / p.mass
+}
+
+// SetBodyType adds the sprite to the physics world as a static, dynamic or
+// kinematic body, or removes it with NoBody. Bodies don't rotate.
+func (p *SpriteImpl) SetBodyType(typ BodyType) {
+	p.body.typ = typ
+}
+
+func (p *SpriteImpl) BodyType() BodyType {
+	return p.body.typ
+}
+
+func (p *SpriteImpl) SetMass(mass float64) {
+	if mass <= 0 {
+		log.Println("SetMass: invalid mass -", mass)
+		return
+	}
+	p.body.mass = mass
+}
+
+func (p *SpriteImpl) Mass() float64 {
+	return p.body.mass
+}
+
+// SetFriction sets the friction coefficient of the body. The friction of a
+// contact is the geometric mean of the frictions of both bodies.
+func (p *SpriteImpl) SetFriction(friction float64) {
+	p.body.friction = friction
+}
+
+// SetRestitution sets the bounciness of the body, from 0 (no bounce) to 1.
+// The restitution of a contact is the larger one of both bodies.
+func (p *SpriteImpl) SetRestitution(restitution float64) {
+	p.body.restitution = restitution
+}
+
+func (p *SpriteImpl) SetGravityScale(scale float64) {
+	p.body.gravityScale = scale
+}
+
+// SetVelocity sets the velocity of the body, in pixels per second.
+func (p *SpriteImpl) SetVelocity(vx, vy float64) {
+	p.body.vx, p.body.vy = vx, vy
+}
+
+func (p *SpriteImpl) Velocity() (vx, vy float64) {
+	return p.body.vx, p.body.vy
+}
+
+// ApplyForce applies a force to the body during the next physics step.
+func (p *SpriteImpl) ApplyForce(fx, fy float64) {
+	p.body.fx += fx
+	p.body.fy += fy
+}
+
+// ApplyImpulse changes the velocity of the body at once, by impulse / mass.
+func (p *SpriteImpl) ApplyImpulse(ix, iy float64) {
+	inv := p.body.invMass()
+	p.body.vx += ix * inv
+	p.body.vy += iy * inv
+}
+
+// physicsShape returns the collision shape of the sprite, or its rotated
+// rect if it collides by its pixels.
+func (p *SpriteImpl) physicsShape() spatial.Shape {
+	if p.collisionShape != nil {
+		return p.collisionShape
+	}
+	if rRect := p.getRotatedRect(); rRect != nil {
+		return polygonOf(rRect)
+	}
+	return nil
+}
+
+// -------------------------------------------------------------------------------------
+
+type physicsWorld struct {
+	gravityX, gravityY float64
+	accum              float64 // time not simulated yet, in seconds
+	grid               *spatial.Grid
+	contacts           map[[2]*SpriteImpl]bool // contacts found in the last update
+}
+
+type physicsContact struct {
+	a, b *SpriteImpl
+	spatial.Contact
+}
+
+func (p *physicsWorld) init(conf *physicsConfig) {
+	p.gravityX, p.gravityY = 0, defaultGravity
+	if conf != nil && conf.Gravity != nil {
+		p.gravityX, p.gravityY = conf.Gravity.X, conf.Gravity.Y
+	}
+	p.reset()
+}
+
+func (p *physicsWorld) reset() {
+	p.accum = 0
+	p.contacts = nil
+}
+
+// SetGravity sets the gravity of the physics world, in pixels/s².
+func (p *Game) SetGravity(x, y float64) {
+	p.physics.gravityX, p.physics.gravityY = x, y
+}
+
+// updatePhysics steps the physics world at a fixed timestep, as many times as
+// needed to catch up with the time of a tick.
+func (p *Game) updatePhysics() {
+	w := &p.physics
+	w.contacts = nil
+	var bodies []*SpriteImpl
+	for _, item := range p.items {
+		if sp, ok := item.(*SpriteImpl); ok && sp.body.typ != NoBody && sp.isVisible && !sp.isDying {
+			bodies = append(bodies, sp)
+		}
+	}
+	if len(bodies) == 0 {
+		w.accum = 0
+		return
+	}
+	w.accum += 1 / p.currentTPS()
+	steps := 0
+	for ; w.accum >= physicsStep && steps < physicsMaxSteps; steps++ {
+		w.step(bodies, physicsStep)
+		w.accum -= physicsStep
+	}
+	if steps == physicsMaxSteps {
+		w.accum = 0
+	}
+}
+
+func (p *physicsWorld) step(bodies []*SpriteImpl, dt float64) {
+	for _, sp := range bodies {
+		b := &sp.body
+		if b.typ == DynamicBody {
+			b.vx += (p.gravityX*b.gravityScale + b.fx/b.mass) * dt
+			b.vy += (p.gravityY*b.gravityScale + b.fy/b.mass) * dt
+		}
+		b.fx, b.fy = 0, 0
+	}
+
+	contacts := p.findContacts(bodies)
+	for i := 0; i < physicsIterations; i++ {
+		for _, c := range contacts {
+			c.solveVelocity(i == 0)
+		}
+	}
+
+	for _, sp := range bodies {
+		if b := &sp.body; b.typ != StaticBody && (b.vx != 0 || b.vy != 0) {
+			sp.doMoveTo(sp.x+b.vx*dt, sp.y+b.vy*dt)
+		}
+	}
+	for _, c := range contacts {
+		c.correctPosition()
+	}
+}
+
+// findContacts returns the contacts between bodies, at least one of which is
+// dynamic, and records them as touching.
+func (p *physicsWorld) findContacts(bodies []*SpriteImpl) []*physicsContact {
+	shapes := make([]spatial.Shape, len(bodies))
+	size := 0.0
+	for i, sp := range bodies {
+		if shapes[i] = sp.physicsShape(); shapes[i] != nil {
+			b := shapes[i].Box()
+			size += math.Max(b.MaxX-b.MinX, b.MaxY-b.MinY)
+		}
+	}
+	cellSize := math.Max(size/float64(len(bodies))*2, broadphaseMinCellSize)
+	if p.grid == nil {
+		p.grid = spatial.NewGrid(cellSize)
+	} else {
+		p.grid.Reset(cellSize)
+	}
+	ids := make([]int, 0, len(bodies))
+	for i, shape := range shapes {
+		if shape != nil {
+			p.grid.Insert(shape.Box())
+			ids = append(ids, i)
+		}
+	}
+
+	var contacts []*physicsContact
+	p.grid.Pairs(func(i, j int) {
+		a, b := bodies[ids[i]], bodies[ids[j]]
+		if a.body.typ != DynamicBody && b.body.typ != DynamicBody || !a.collidesWith(b) {
+			return
+		}
+		if c, ok := spatial.Collide(shapes[ids[i]], shapes[ids[j]]); ok {
+			contacts = append(contacts, &physicsContact{a, b, c})
+			if p.contacts == nil {
+				p.contacts = make(map[[2]*SpriteImpl]bool)
+			}
+			p.contacts[[2]*SpriteImpl{a, b}] = true
+		}
+	})
+	return contacts
+}
+
+// solveVelocity applies the normal and friction impulses of the contact.
+// Bodies only bounce in the first iteration.
+func (c *physicsContact) solveVelocity(bounce bool) {
+	a, b := &c.a.body, &c.b.body
+	invA, invB := a.invMass(), b.invMass()
+	invSum := invA + invB
+	if invSum == 0 {
+		return
+	}
+	rvx, rvy := b.vx-a.vx, b.vy-a.vy
+	vn := rvx*c.NX + rvy*c.NY
+	if vn > 0 { // separating
+		return
+	}
+	e := 0.0
+	if bounce && -vn > physicsBounceMin {
+		e = math.Max(a.restitution, b.restitution)
+	}
+	j := -(1 + e) * vn / invSum
+	a.vx, a.vy = a.vx-j*invA*c.NX, a.vy-j*invA*c.NY
+	b.vx, b.vy = b.vx+j*invB*c.NX, b.vy+j*invB*c.NY
+
+	// friction along the tangent, bounded by the normal impulse
+	rvx, rvy = b.vx-a.vx, b.vy-a.vy
+	tx, ty := -c.NY, c.NX
+	jt := -(rvx*tx + rvy*ty) / invSum
+	limit := j * math.Sqrt(a.friction*b.friction)
+	jt = math.Max(-limit, math.Min(limit, jt))
+	a.vx, a.vy = a.vx-jt*invA*tx, a.vy-jt*invA*ty
+	b.vx, b.vy = b.vx+jt*invB*tx, b.vy+jt*invB*ty
+}
+
+// correctPosition pushes the bodies apart, to resolve the penetration which
+// the velocity solver left.
+func (c *physicsContact) correctPosition() {
+	invA, invB := c.a.body.invMass(), c.b.body.invMass()
+	invSum := invA + invB
+	depth := c.Depth - physicsSlop
+	if invSum == 0 || depth <= 0 {
+		return
+	}
+	d := depth * physicsCorrection / invSum
+	if invA != 0 {
+		c.a.doMoveTo(c.a.x-d*invA*c.NX, c.a.y-d*invA*c.NY)
+	}
+	if invB != 0 {
+		c.b.doMoveTo(c.b.x+d*invB*c.NX, c.b.y+d*invB*c.NY)
+	}
+}
+
+// -------------------------------------------------------------------------------------
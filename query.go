@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"math"
+
+	"github.com/goplus/spx/internal/spatial"
+)
+
+// -------------------------------------------------------------------------------------
+
+// RaycastHit is the first sprite hit by a ray.
+type RaycastHit struct {
+	Sprite           Sprite
+	X, Y             float64 // hit point
+	NormalX, NormalY float64 // unit normal of the sprite surface at the hit point
+	Distance         float64 // from the origin of the ray to the hit point
+}
+
+// querySprites calls fn with the visible sprites which bounds may overlap
+// box. Queries have their own grid, which updateColliders doesn't rebuild
+// under them.
+func (p *Game) querySprites(box spatial.Box, fn func(sp *SpriteImpl)) {
+	g := &p.queryGrid
+	if g.grid == nil || p.queryDirty {
+		g.build(p.items)
+		p.queryDirty = false
+	}
+	sprites := g.sprites
+	g.grid.Query(box, func(id int) {
+		if sp := sprites[id]; sp.isVisible && !sp.isDying {
+			fn(sp)
+		}
+	})
+}
+
+// SpritesInRect returns the visible sprites overlapping the rect, which
+// bottom-left corner is (x, y). Sprites without a collider are tested by
+// their pixels.
+func (p *Game) SpritesInRect(x, y, width, height float64) []Sprite {
+	rect := spatial.Polygon{{X: x, Y: y}, {X: x + width, Y: y}, {X: x + width, Y: y + height}, {X: x, Y: y + height}}
+	return p.spritesIn(rect)
+}
+
+// SpritesInCircle returns the visible sprites overlapping the circle
+// centered at (x, y).
+func (p *Game) SpritesInCircle(x, y, radius float64) []Sprite {
+	return p.spritesIn(&spatial.Circle{X: x, Y: y, R: radius})
+}
+
+func (p *Game) spritesIn(shape spatial.Shape) (ret []Sprite) {
+	p.querySprites(shape.Box(), func(sp *SpriteImpl) {
+		if sp.touchesShape(shape) {
+			ret = append(ret, sp.sprite)
+		}
+	})
+	return
+}
+
+// Raycast casts a ray from (x, y) in the direction (dirX, dirY), and returns
+// the first visible sprite hit within maxDist. Sprites for which filter
+// returns false are ignored, filter can be nil.
+func (p *Game) Raycast(x, y, dirX, dirY, maxDist float64, filter func(sp Sprite) bool) (hit RaycastHit, ok bool) {
+	l := math.Hypot(dirX, dirY)
+	if l == 0 || maxDist <= 0 {
+		return
+	}
+	dx, dy := dirX/l, dirY/l
+	ex, ey := x+dx*maxDist, y+dy*maxDist
+	box := spatial.Box{MinX: math.Min(x, ex), MinY: math.Min(y, ey), MaxX: math.Max(x, ex), MaxY: math.Max(y, ey)}
+	hit.Distance = maxDist
+	p.querySprites(box, func(sp *SpriteImpl) {
+		if filter != nil && !filter(sp.sprite) {
+			return
+		}
+		if dist, nx, ny, found := sp.raycast(x, y, dx, dy, hit.Distance); found {
+			hit = RaycastHit{Sprite: sp.sprite, NormalX: nx, NormalY: ny, Distance: dist}
+			ok = true
+		}
+	})
+	if ok {
+		hit.X, hit.Y = x+dx*hit.Distance, y+dy*hit.Distance
+	}
+	return
+}
+
+// raycast returns where the ray from (x, y) in the unit direction (dx, dy)
+// hits p within maxDist. A sprite without a collider is hit at its first
+// non-transparent pixel along the ray, the normal is estimated from its
+// neighbor pixels.
+func (p *SpriteImpl) raycast(x, y, dx, dy, maxDist float64) (dist, nx, ny float64, ok bool) {
+	shape := p.physicsShape()
+	if shape == nil {
+		return
+	}
+	hit, ok := spatial.Ray(shape, x, y, dx, dy)
+	if !ok || hit.Enter > maxDist {
+		return 0, 0, 0, false
+	}
+	if p.collisionShape != nil {
+		return hit.Enter, hit.NX, hit.NY, true
+	}
+	exit := math.Min(hit.Exit, maxDist)
+	for t := hit.Enter; t <= exit; t++ {
+		px, py := x+dx*t, y+dy*t
+		if !p.touchPoint(px, py) {
+			continue
+		}
+		nx, ny = p.pixelNormal(px, py)
+		if nx == 0 && ny == 0 {
+			nx, ny = -dx, -dy
+		}
+		return t, nx, ny, true
+	}
+	return 0, 0, 0, false
+}
+
+// pixelNormal estimates the normal of the sprite surface at (x, y), pointing
+// away from its non-transparent pixels.
+func (p *SpriteImpl) pixelNormal(x, y float64) (nx, ny float64) {
+	opaque := func(x, y float64) float64 {
+		if p.touchPoint(x, y) {
+			return 1
+		}
+		return 0
+	}
+	nx = opaque(x-1, y) - opaque(x+1, y)
+	ny = opaque(x, y-1) - opaque(x, y+1)
+	if l := math.Hypot(nx, ny); l != 0 {
+		nx, ny = nx/l, ny/l
+	}
+	return
+}
+
+// -------------------------------------------------------------------------------------
@@ -0,0 +1,238 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"path"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	spxfs "github.com/goplus/spx/fs"
+	"github.com/goplus/spx/internal/effect"
+	"github.com/goplus/spx/internal/gdi"
+	"github.com/goplus/spx/internal/math32"
+	"github.com/goplus/spx/internal/skeleton"
+)
+
+// -------------------------------------------------------------------------------------
+
+const skeletonFps = 60.0
+
+// spriteSkeleton is the skeleton of a sprite animated by bones.
+type spriteSkeleton struct {
+	sk     *skeleton.Skeleton
+	skin   string
+	images map[string]*delayloadImage // images of attachments, by their paths
+
+	anim *skeleton.Animation // posing the skeleton, nil for the setup pose
+	time float64
+
+	parts []*skeleton.Part // posed attachments, in stage coordinates
+
+	// pose and transform of parts
+	partsAnim *skeleton.Animation
+	partsTime float64
+	partsGeo  ebiten.GeoM
+}
+
+func newSpriteSkeleton(base string, fs spxfs.Dir, conf *skeletonConfig) *spriteSkeleton {
+	file := path.Join(base, conf.Path)
+	d, err := skeleton.Load(fs.Open, file)
+	if err != nil {
+		panic(err)
+	}
+	dir := path.Join(base, conf.Images)
+	if conf.Images == "" && !path.IsAbs(d.Images) {
+		dir = path.Join(path.Dir(file), d.Images)
+	}
+	images := make(map[string]*delayloadImage)
+	for _, skin := range d.Skins {
+		for _, atts := range skin {
+			for _, att := range atts {
+				if _, ok := images[att.Path]; !ok {
+					loader := imageLoaderByPath(path.Join(dir, att.Path+".png"))
+					images[att.Path] = &delayloadImage{loader: loader}
+				}
+			}
+		}
+	}
+	s := &spriteSkeleton{sk: skeleton.New(d), skin: conf.Skin, images: images}
+	if conf.Skin != "" && !s.sk.SetSkin(conf.Skin) {
+		log.Panicf("skeleton %s: skin %s not found", file, conf.Skin)
+	}
+	return s
+}
+
+func (p *spriteSkeleton) clone() *spriteSkeleton {
+	ret := &spriteSkeleton{sk: skeleton.New(p.sk.Data), skin: p.skin, images: p.images, anim: p.anim, time: p.time}
+	if p.skin != "" {
+		ret.sk.SetSkin(p.skin)
+	}
+	return ret
+}
+
+// pose sets the skeleton to the pose of animation name at time t in seconds.
+func (p *spriteSkeleton) pose(name string, t float64) {
+	p.anim, p.time = p.sk.Data.Animations[name], t
+}
+
+func (p *spriteSkeleton) image(fs spxfs.Dir, name string) gdi.Image {
+	img := p.images[name]
+	img.ensure(fs)
+	return img.cache
+}
+
+// initWithSkeleton gives a sprite drawn by its skeleton a transparent costume.
+func (p *baseObj) initWithSkeleton() {
+	img := gdi.NewImageFrom(image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	p.costumes = []*costume{{name: "skeleton", img: delayloadImage{cache: img}, bitmapResolution: 1}}
+	p.costumeIndex_ = 0
+}
+
+// addSkeletonAnimations adds the animations of the skeleton of p, unless
+// animations with the same names are defined.
+func (p *SpriteImpl) addSkeletonAnimations() {
+	for name, a := range p.skeleton.sk.Data.Animations {
+		if _, ok := p.animations[name]; ok {
+			continue
+		}
+		duration := math.Max(a.Duration, 2/skeletonFps)
+		p.animations[name] = &aniConfig{
+			AniType: aniTypeSkeleton, Fps: skeletonFps,
+			From: 0.0, To: a.Duration, Duration: duration,
+		}
+	}
+}
+
+// updateSkeleton poses the skeleton of p and transforms its attachments to the
+// stage, unless its pose and transform didn't change.
+func (p *SpriteImpl) updateSkeleton() {
+	s := p.skeleton
+	geo := ebiten.GeoM{}
+	geo.Translate(-p.pivot.X, -p.pivot.Y)
+	geo.Scale(p.scale, p.scale)
+	if p.rotationStyle == Normal {
+		geo.Rotate(-toRadian(p.direction - 90)) // y points up
+	} else if p.rotationStyle == LeftRight && normalizeDirection(p.direction) < 0 {
+		geo.Scale(-1, 1)
+	}
+	geo.Translate(p.x, p.y)
+	if s.parts != nil && s.partsAnim == s.anim && s.partsTime == s.time && s.partsGeo == geo {
+		return
+	}
+	s.partsAnim, s.partsTime, s.partsGeo = s.anim, s.time, geo
+
+	if s.anim != nil {
+		s.sk.Apply(s.anim, s.time)
+	} else {
+		s.sk.SetToSetupPose()
+		s.sk.UpdateWorldTransform()
+	}
+
+	minX, minY, maxX, maxY := p.x, p.y, p.x, p.y
+	s.parts = s.sk.Parts()
+	for i, part := range s.parts {
+		vs := part.Vertices
+		for j := 0; j < len(vs); j += 2 {
+			x, y := geo.Apply(vs[j], vs[j+1])
+			vs[j], vs[j+1] = x, y
+			if i == 0 && j == 0 {
+				minX, minY, maxX, maxY = x, y, x, y
+				continue
+			}
+			minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+			minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+		}
+	}
+	p.rRect = math32.NewRotatedRect1(math32.NewRect(minX, minY, maxX-minX, maxY-minY))
+	p.collisionShape = nil
+	p.g.queryDirty = true
+}
+
+// drawSkeleton draws the posed attachments of the skeleton of p.
+func (p *SpriteImpl) drawSkeleton(dc drawContext) {
+	p.drawSkeletonParts(dc, p.skeleton.parts, p.greffUniforms)
+}
+
+// drawSkeletonParts draws attachments of the skeleton of p, posed in stage
+// coordinates, with graphic effects effs.
+func (p *SpriteImpl) drawSkeletonParts(dc drawContext, parts []*skeleton.Part, effs map[string]interface{}) {
+	worldW, worldH := p.g.worldSize_()
+	ox, oy := float64(worldW)/2, float64(worldH)/2
+
+	var shader *ebiten.Shader
+	if effs != nil {
+		s, err := ebiten.NewShader(effect.ShaderFrag)
+		if err != nil {
+			panic(err)
+		}
+		shader = s
+	}
+	for _, part := range parts {
+		img := p.skeleton.image(p.g.fs, part.Image)
+		b := img.Bounds()
+		w, h := float64(b.Dx()), float64(b.Dy())
+		c := part.Color
+		vs := make([]ebiten.Vertex, len(part.Vertices)/2)
+		for i := range vs {
+			vs[i] = ebiten.Vertex{
+				DstX:   float32(ox + part.Vertices[i*2]),
+				DstY:   float32(oy - part.Vertices[i*2+1]),
+				SrcX:   float32(float64(b.Min.X) + part.UVs[i*2]*w),
+				SrcY:   float32(float64(b.Min.Y) + part.UVs[i*2+1]*h),
+				ColorR: float32(c.R), ColorG: float32(c.G), ColorB: float32(c.B), ColorA: float32(c.A),
+			}
+		}
+		if shader != nil {
+			op := new(ebiten.DrawTrianglesShaderOptions)
+			op.Uniforms = effs
+			op.Images[0] = img.Ebiten()
+			dc.DrawTrianglesShader(vs, part.Triangles, shader, op)
+		} else {
+			op := new(ebiten.DrawTrianglesOptions)
+			op.Filter = ebiten.FilterLinear
+			dc.DrawTriangles(vs, part.Triangles, img.Ebiten(), op)
+		}
+	}
+}
+
+// skeletonPixel returns the color of the posed skeleton of p at (x, y) in
+// stage coordinates, testing the deformed meshes of its attachments.
+func (p *SpriteImpl) skeletonPixel(x, y float64) color.Color {
+	parts := p.skeleton.parts
+	for i := len(parts) - 1; i >= 0; i-- {
+		part := parts[i]
+		u, v, ok := part.Locate(x, y)
+		if !ok {
+			continue
+		}
+		img := p.skeleton.image(p.g.fs, part.Image).Origin()
+		b := img.Bounds()
+		px := b.Min.X + int(math.Min(u*float64(b.Dx()), float64(b.Dx()-1)))
+		py := b.Min.Y + int(math.Min(v*float64(b.Dy()), float64(b.Dy()-1)))
+		if c := img.RGBAAt(px, py); c.A != 0 {
+			return c
+		}
+	}
+	return color.Transparent
+}
+
+// -------------------------------------------------------------------------------------
@@ -28,6 +28,7 @@ import (
 	"github.com/pkg/errors"
 
 	spxfs "github.com/goplus/spx/fs"
+	"github.com/goplus/spx/internal/atlas"
 	"github.com/goplus/spx/internal/gdi"
 )
 
@@ -119,6 +120,18 @@ type sharedImages struct {
 	imgs map[string]gdi.Image
 }
 
+func (p *sharedImages) load(fs spxfs.Dir, path string) (img gdi.Image, err error) {
+	img, ok := p.imgs[path]
+	if !ok {
+		var tmp imagePoint
+		if img, err = imageLoaderByPath(path).load(fs, &tmp); err != nil {
+			return
+		}
+		p.imgs[path] = img
+	}
+	return
+}
+
 type sharedImage struct {
 	shared *sharedImages
 	path   string
@@ -126,14 +139,9 @@ type sharedImage struct {
 }
 
 func (p *sharedImage) load(fs spxfs.Dir, pt *imagePoint) (ret gdi.Image, err error) {
-	path := p.path
-	shared, ok := p.shared.imgs[path]
-	if !ok {
-		var tmp imagePoint
-		if shared, err = imageLoaderByPath(path).load(fs, &tmp); err != nil {
-			return
-		}
-		p.shared.imgs[path] = shared
+	shared, err := p.shared.load(fs, p.path)
+	if err != nil {
+		return
 	}
 	rc := p.rc
 	min := image.Point{X: int(rc.X), Y: int(rc.Y)}
@@ -150,6 +158,54 @@ func (p *sharedImage) load(fs spxfs.Dir, pt *imagePoint) (ret gdi.Image, err err
 
 // -------------------------------------------------------------------------------------
 
+// atlasFrameLoader loads a frame of a sprite sheet atlas. The center of the
+// costume is the pivot of the frame, so trimmed frames keep their positions.
+type atlasFrameLoader struct {
+	shared *sharedImages
+	path   string
+	frame  *atlas.Frame
+}
+
+func (p *atlasFrameLoader) load(fs spxfs.Dir, pt *imagePoint) (ret gdi.Image, err error) {
+	sheet, err := p.shared.load(fs, p.path)
+	if err != nil {
+		return
+	}
+	f := p.frame
+	rc := f.Rect
+	w, h := rc.W, rc.H
+	if f.Rotated {
+		w, h = h, w
+	}
+	img := sheet.SubImage(image.Rect(rc.X, rc.Y, rc.X+w, rc.Y+h))
+	if !img.IsValid() {
+		panic("disposed image")
+	}
+	if f.Rotated {
+		img = gdi.NewImageFrom(unrotateFrame(img.Origin()))
+	}
+	if pt != nil {
+		pt.x = f.PivotX*float64(f.SourceW) - float64(f.Source.X)
+		pt.y = f.PivotY*float64(f.SourceH) - float64(f.Source.Y)
+	}
+	return img, nil
+}
+
+// unrotateFrame turns a frame stored turned 90 degrees clockwise back.
+func unrotateFrame(src *image.RGBA) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dy(), b.Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dst.SetRGBA(x, y, src.RGBAAt(b.Min.X+h-1-y, b.Min.Y+x))
+		}
+	}
+	return dst
+}
+
+// -------------------------------------------------------------------------------------
+
 type imageLoaderByCostumeSet struct {
 	costumeSet *costumeSetImage
 	index      int
@@ -178,6 +234,7 @@ type costume struct {
 	img              delayloadImage
 	faceRight        float64
 	bitmapResolution int
+	collider         *colliderConfig // collides by pixels if nil
 }
 
 func newCostumeWithSize(width, height int) *costume {
@@ -207,6 +264,7 @@ func newCostume(base string, c *costumeConfig) *costume {
 		img:              delayloadImage{loader: loader, pt: imagePoint{c.X, c.Y}},
 		faceRight:        c.FaceRight,
 		bitmapResolution: toBitmapResolution(c.BitmapResolution),
+		collider:         c.Collider,
 	}
 }
 
@@ -237,7 +295,7 @@ func (p *baseObj) initWith(base string, sprite *spriteConfig, shared *sharedImag
 	} else if sprite.CostumeMPSet != nil {
 		initWithCMPS(p, base, sprite.CostumeMPSet, shared)
 	} else {
-		panic("sprite.init should have one of costumes, costumeSet and costumeMPSet")
+		panic("sprite.init should have one of costumes, costumeSet, costumeMPSet and costumeAtlas")
 	}
 	nx := len(p.costumes)
 	costumeIndex := sprite.getCostumeIndex()
@@ -247,6 +305,27 @@ func (p *baseObj) initWith(base string, sprite *spriteConfig, shared *sharedImag
 	p.costumeIndex_ = costumeIndex
 }
 
+func (p *baseObj) initWithAtlas(base string, fs spxfs.Dir, conf *costumeAtlas, costumeIndex int, shared *sharedImages) *atlas.Atlas {
+	sheet, err := atlas.Load(fs.Open, path.Join(base, conf.Path))
+	if err != nil {
+		panic(err)
+	}
+	faceRight, bitmapResolution := conf.FaceRight, toBitmapResolution(conf.BitmapResolution)
+	p.costumes = make([]*costume, len(sheet.Frames))
+	for i, f := range sheet.Frames {
+		loader := &atlasFrameLoader{shared: shared, path: sheet.Image, frame: f}
+		p.costumes[i] = &costume{
+			name: f.Name, img: delayloadImage{loader: loader},
+			faceRight: faceRight, bitmapResolution: bitmapResolution,
+		}
+	}
+	if costumeIndex >= len(p.costumes) || costumeIndex < 0 {
+		costumeIndex = 0
+	}
+	p.costumeIndex_ = costumeIndex
+	return sheet
+}
+
 func initWithCMPS(p *baseObj, base string, cmps *costumeMPSet, shared *sharedImages) {
 	faceRight, bitmapResolution := cmps.FaceRight, toBitmapResolution(cmps.BitmapResolution)
 	imgPath := path.Join(base, cmps.Path)
@@ -26,6 +26,7 @@ import (
 	"github.com/goplus/spx/internal/effect"
 	"github.com/goplus/spx/internal/gdi"
 	"github.com/goplus/spx/internal/math32"
+	"github.com/goplus/spx/internal/spatial"
 
 	"github.com/hajimehoshi/ebiten/v2"
 
@@ -159,6 +160,10 @@ func (p *spriteDrawInfo) getUpdateRotateRect(x, y float64) *math32.RotatedRect {
 }
 
 func (p *spriteDrawInfo) updateMatrix() {
+	if p.sprite.skeleton != nil {
+		p.sprite.updateSkeleton()
+		return
+	}
 	c := p.sprite.costumes[p.sprite.costumeIndex_]
 
 	img, centerX, centerY := c.needImage(p.sprite.g.fs)
@@ -200,7 +205,14 @@ func (p *spriteDrawInfo) updateMatrix() {
 
 	geo2 := geo
 	geo2.Scale(1.0, -1.0)
-	p.sprite.rRect = math32.ApplyGeoForRotatedRect(rect, &geo2)
+	sp := p.sprite
+	if sp.rRect == nil || geo2 != sp.rRectGeo || c != sp.rRectCostume {
+		sp.rRect = math32.ApplyGeoForRotatedRect(rect, &geo2)
+		sp.collisionShape = newCollisionShape(c.collider,
+			float64(rect.Max.X), float64(rect.Max.Y), centerX, centerY, &geo2, scale)
+		sp.rRectGeo, sp.rRectCostume = geo2, c
+		sp.g.queryDirty = true
+	}
 	geo.Translate(float64(worldW>>1), float64(wolrdH>>1))
 	p.geo = geo
 }
@@ -209,15 +221,43 @@ func (p *spriteDrawInfo) doDrawOn(dc drawContext, fs spxfs.Dir) {
 	if !p.visible {
 		return
 	}
+	if p.sprite.skeleton != nil {
+		p.updateMatrix()
+		p.sprite.drawSkeleton(dc)
+		return
+	}
 
 	c := p.sprite.costumes[p.sprite.costumeIndex_]
 	img, _, _ := c.needImage(fs)
 
 	p.updateMatrix()
+	drawCostume(dc, img, p.geo, p.sprite.greffUniforms)
+}
+
+// snapshot returns a function drawing the sprite as it is now, with its
+// graphic effects.
+func (p *spriteDrawInfo) snapshot() func(dc drawContext) {
+	sp := p.sprite
+	p.updateMatrix()
+	effs := cloneMap(sp.greffUniforms)
+	if sp.skeleton != nil {
+		parts := sp.skeleton.parts
+		return func(dc drawContext) {
+			sp.drawSkeletonParts(dc, parts, effs)
+		}
+	}
+	c := sp.costumes[sp.costumeIndex_]
+	img, _, _ := c.needImage(sp.g.fs)
+	geo := p.geo
+	return func(dc drawContext) {
+		drawCostume(dc, img, geo, effs)
+	}
+}
 
-	if effs := p.sprite.greffUniforms; effs != nil {
+func drawCostume(dc drawContext, img gdi.Image, geo ebiten.GeoM, effs map[string]interface{}) {
+	if effs != nil {
 		op := new(ebiten.DrawRectShaderOptions)
-		op.GeoM = p.geo
+		op.GeoM = geo
 		op.Uniforms = effs
 		s, err := ebiten.NewShader(effect.ShaderFrag)
 		if err != nil {
@@ -229,7 +269,7 @@ func (p *spriteDrawInfo) doDrawOn(dc drawContext, fs spxfs.Dir) {
 	} else {
 		op := new(ebiten.DrawImageOptions)
 		op.Filter = ebiten.FilterLinear
-		op.GeoM = p.geo
+		op.GeoM = geo
 		dc.DrawImage(img.Ebiten(), op)
 	}
 }
@@ -241,22 +281,39 @@ func (p *SpriteImpl) getDrawInfo() *spriteDrawInfo {
 	}
 }
 
+// pixelReader returns a function getting the color of the sprite at a
+// position in stage coordinates.
+func (p *SpriteImpl) pixelReader() func(pos *math32.Vector2) color.Color {
+	if p.skeleton != nil {
+		return func(pos *math32.Vector2) color.Color {
+			return p.skeletonPixel(pos.X, pos.Y)
+		}
+	}
+	c := p.costumes[p.costumeIndex_]
+	img, cx, cy := c.needImage(p.g.fs)
+	p.applyPivot(c, &cx, &cy)
+	di := p.getDrawInfo()
+	geo := di.getPixelGeo(cx, cy)
+	return func(pos *math32.Vector2) color.Color {
+		pixel, _ := di.getPixel(pos, img, geo)
+		return pixel
+	}
+}
+
 func (p *SpriteImpl) touchPoint(x, y float64) bool {
 	rRect := p.getRotatedRect()
 	if rRect == nil {
 		return false
 	}
+	if p.collisionShape != nil {
+		return p.collisionShape.Contains(x, y)
+	}
 	pos := &math32.Vector2{X: x, Y: y}
 	ret := rRect.Contains(pos)
 	if !ret {
 		return false
 	}
-	c := p.costumes[p.costumeIndex_]
-	img, cx, cy := c.needImage(p.g.fs)
-	p.applyPivot(c, &cx, &cy)
-	geo := p.getDrawInfo().getPixelGeo(cx, cy)
-
-	pixel, _ := p.getDrawInfo().getPixel(pos, img, geo)
+	pixel := p.pixelReader()(pos)
 	if reflect.DeepEqual(pixel, color.Transparent) {
 		return false
 	}
@@ -271,6 +328,9 @@ func (p *SpriteImpl) touchRotatedRect(dstRect *math32.RotatedRect) bool {
 	if currRect == nil {
 		return false
 	}
+	if p.collisionShape != nil {
+		return spatial.Overlap(p.collisionShape, polygonOf(dstRect))
+	}
 	ret := currRect.IsCollision(dstRect)
 	if !ret {
 		return false
@@ -284,15 +344,12 @@ func (p *SpriteImpl) touchRotatedRect(dstRect *math32.RotatedRect) bool {
 		log.Printf("touchRotatedRect  currBoundRect(%s) dstRectBoundRect(%s) boundRect(%s)",
 			currBoundRect.String(), dstRectBoundRect.String(), boundRect.String())
 	}
-	c := p.costumes[p.costumeIndex_]
-	img, cx, cy := c.needImage(p.g.fs)
-	p.applyPivot(c, &cx, &cy)
-	geo := p.getDrawInfo().getPixelGeo(cx, cy)
+	pixelAt := p.pixelReader()
 
 	//check boun rect pixel
 	for x := boundRect.X; x < boundRect.Width+boundRect.X; x++ {
 		for y := boundRect.Y; y < boundRect.Height+boundRect.Y; y++ {
-			color1, _ := p.getDrawInfo().getPixel(math32.NewVector2(x, y), img, geo)
+			color1 := pixelAt(math32.NewVector2(x, y))
 			_, _, _, a := color1.RGBA()
 			if a != 0 {
 				return true
@@ -321,23 +378,15 @@ func (p *SpriteImpl) touchedColor_(dst *SpriteImpl, color Color) bool {
 	dstRectBoundRect := dstRect.BoundingRect()
 	boundRect := currBoundRect.Intersect(dstRectBoundRect)
 
-	c := p.costumes[p.costumeIndex_]
-	pimg, cx, cy := c.needImage(p.g.fs)
-	p.applyPivot(c, &cx, &cy)
-	geo := p.getDrawInfo().getPixelGeo(cx, cy)
-
-	c2 := dst.costumes[dst.costumeIndex_]
-	dstimg, cx2, cy2 := c2.needImage(p.g.fs)
-	dst.applyPivot(c2, &cx2, &cy2)
-	geo2 := dst.getDrawInfo().getPixelGeo(cx2, cy2)
+	pixelAt, dstPixelAt := p.pixelReader(), dst.pixelReader()
 
 	cr, cg, cb, ca := color.RGBA()
 	//check boun rect pixel
 	for x := boundRect.X; x < boundRect.Width+boundRect.X; x++ {
 		for y := boundRect.Y; y < boundRect.Height+boundRect.Y; y++ {
 			pos := math32.NewVector2(x, y)
-			color1, _ := p.getDrawInfo().getPixel(pos, pimg, geo)
-			color2, _ := dst.getDrawInfo().getPixel(pos, dstimg, geo2)
+			color1 := pixelAt(pos)
+			color2 := dstPixelAt(pos)
 			_, _, _, a1 := color1.RGBA()
 			r, g, b, a2 := color2.RGBA()
 			if a1 != 0 && a2 != 0 && r == cr && g == cg && b == cb && a2 == ca {
@@ -357,6 +406,9 @@ func (p *SpriteImpl) touchingSprite(dst *SpriteImpl) bool {
 	if dstRect == nil {
 		return false
 	}
+	if p.collisionShape != nil || dst.collisionShape != nil {
+		return p.touchingShape(dst)
+	}
 	ret := currRect.IsCollision(dstRect)
 	if !ret {
 		return false
@@ -371,21 +423,13 @@ func (p *SpriteImpl) touchingSprite(dst *SpriteImpl) bool {
 			p.x, p.y, currRect, currBoundRect, dst.x, dst.y, dstRect, dstRectBoundRect, boundRect)
 	}
 
-	c := p.costumes[p.costumeIndex_]
-	pimg, cx, cy := c.needImage(p.g.fs)
-	p.applyPivot(c, &cx, &cy)
-	geo := p.getDrawInfo().getPixelGeo(cx, cy)
-
-	c2 := dst.costumes[dst.costumeIndex_]
-	dstimg, cx2, cy2 := c2.needImage(p.g.fs)
-	dst.applyPivot(c2, &cx2, &cy2)
-	geo2 := dst.getDrawInfo().getPixelGeo(cx2, cy2)
+	pixelAt, dstPixelAt := p.pixelReader(), dst.pixelReader()
 	//check boun rect pixel
 	for x := boundRect.X; x < boundRect.Width+boundRect.X; x++ {
 		for y := boundRect.Y; y < boundRect.Height+boundRect.Y; y++ {
 			pos := math32.NewVector2(x, y)
-			color1, _ := p.getDrawInfo().getPixel(pos, pimg, geo)
-			color2, _ := dst.getDrawInfo().getPixel(pos, dstimg, geo2)
+			color1 := pixelAt(pos)
+			color2 := dstPixelAt(pos)
 			_, _, _, a1 := color1.RGBA()
 			_, _, _, a2 := color2.RGBA()
 			if a1 != 0 && a2 != 0 {
@@ -441,6 +485,9 @@ func (p *SpriteImpl) draw(dc drawContext) {
 	if !di.visible {
 		return
 	}
+	if t := p.trail; t != nil {
+		p.drawTrail(dc, t)
+	}
 	di.draw(dc, p)
 }
 
@@ -451,21 +498,26 @@ func (p *SpriteImpl) hit(hc hitContext) (hr hitResult, ok bool) {
 		return
 	}
 
-	pos := p.g.Camera.screenToWorld(math32.NewVector2(float64(hc.Pos.X), float64(hc.Pos.Y)))
+	cam, pos := p.g.cameraAt(hc.Pos.X, hc.Pos.Y)
+	if !cam.shows(p) {
+		return
+	}
 	worldW, wolrdH := p.g.worldSize_()
 	pos = &math32.Vector2{
 		X: float64(pos.X) - float64(worldW)/2.0,
 		Y: float64(pos.Y) - float64(wolrdH)/2.0,
 	}
 	pos.Y = -pos.Y
+	if p.collisionShape != nil {
+		if p.collisionShape.Contains(pos.X, pos.Y) {
+			return hitResult{Target: p}, true
+		}
+		return
+	}
 	if !rRect.Contains(pos) {
 		return
 	}
-	c := p.costumes[p.costumeIndex_]
-	img, cx, cy := c.needImage(p.g.fs)
-	p.applyPivot(c, &cx, &cy)
-	geo := p.getDrawInfo().getPixelGeo(cx, cy)
-	color1, pos := p.getDrawInfo().getPixel(pos, img, geo)
+	color1 := p.pixelReader()(pos)
 	if debugInstr {
 		log.Printf("hit color1(%v) p(%s)", color1, pos)
 	}
@@ -24,9 +24,13 @@ import (
 	"sync"
 
 	"github.com/goplus/spx/internal/anim"
+	"github.com/goplus/spx/internal/atlas"
 	"github.com/goplus/spx/internal/gdi/clrutil"
+	"github.com/goplus/spx/internal/gdi/hsv"
 	"github.com/goplus/spx/internal/math32"
+	"github.com/goplus/spx/internal/spatial"
 	"github.com/goplus/spx/internal/tools"
+	"github.com/hajimehoshi/ebiten/v2"
 )
 
 type specialDir = int
@@ -59,6 +63,8 @@ const (
 	AnimChannelTurn  string = "@turn"
 	AnimChannelGlide string = "@glide"
 	AnimChannelMove  string = "@move"
+
+	AnimChannelSkeleton string = "@skeleton"
 )
 
 type Collider struct {
@@ -95,6 +101,21 @@ func (c *Collider) SetTouching(other *SpriteImpl, on bool) {
 	}
 }
 
+// touchingSprites returns the sprites which are touching c.
+func (c *Collider) touchingSprites() []*SpriteImpl {
+	c.othersM.Lock()
+	defer c.othersM.Unlock()
+
+	if len(c.others) == 0 {
+		return nil
+	}
+	ret := make([]*SpriteImpl, 0, len(c.others))
+	for other := range c.others {
+		ret = append(ret, other)
+	}
+	return ret
+}
+
 func (c *Collider) Reset() {
 	copy := make(map[*SpriteImpl]bool, len(c.others))
 	for k, v := range c.others {
@@ -233,12 +254,20 @@ type SpriteImpl struct {
 	sprite Sprite
 	name   string
 
-	x, y          float64
-	scale         float64
-	direction     float64
-	rotationStyle RotationStyle
-	rRect         *math32.RotatedRect
-	pivot         math32.Vector2
+	x, y           float64
+	scale          float64
+	direction      float64
+	rotationStyle  RotationStyle
+	rRect          *math32.RotatedRect
+	rRectGeo       ebiten.GeoM // transform and costume which rRect is computed for
+	rRectCostume   *costume
+	collisionShape spatial.Shape // in stage coordinates, nil if colliding by pixels
+	collisionLayer uint32        // collision categories, see SetCollisionLayer
+	collisionMask  uint32        // categories collided with, see SetCollisionMask
+	body           rigidBody
+	pivot          math32.Vector2
+	renderLayer    int // 0..31, see Camera.SetLayerMask
+	zLayer         int // index of the z-order layer, see SetLayer
 
 	sayObj           *sayOrThinker
 	quoteObj         *quoter
@@ -247,10 +276,16 @@ type SpriteImpl struct {
 	animBindings     map[string]string
 	defaultAnimation SpriteAnimationName
 
-	penColor color.RGBA
-	penShade float64
-	penHue   float64
-	penWidth float64
+	penColor      color.RGBA
+	penParams     [numPenColorParams]float64 // see PenColorParam
+	penShade      float64                    // shade of Scratch 2, from 0 to 200
+	penWidth      float64
+	penCap        PenCap
+	penDash       float64 // length of dashes, 0 for solid lines
+	penGap        float64
+	penDashOffset float64 // length drawn in the dash pattern
+
+	trail *spriteTrail
 
 	isVisible bool
 	isCloned_ bool
@@ -265,8 +300,9 @@ type SpriteImpl struct {
 	hasOnTouchEnd   bool
 
 	gamer               reflect.Value
-	lastAnim            *anim.Anim
-	isWaitingStopAnim   bool
+	playing             map[string]*Animation // animations playing, by their channels
+	animator            *Animator
+	skeleton            *spriteSkeleton // drawn instead of costumes if not nil
 	defaultCostumeIndex int
 
 	collider Collider
@@ -286,8 +322,13 @@ func (p *SpriteImpl) getAllShapes() []Shape {
 
 func (p *SpriteImpl) init(
 	base string, g *Game, name string, spriteCfg *spriteConfig, gamer reflect.Value, shared *sharedImages, sprite Sprite) {
+	var sheet *atlas.Atlas
 	if spriteCfg.Costumes != nil {
 		p.baseObj.init(base, spriteCfg.Costumes, spriteCfg.getCostumeIndex())
+	} else if spriteCfg.CostumeAtlas != nil {
+		sheet = p.baseObj.initWithAtlas(base, g.fs, spriteCfg.CostumeAtlas, spriteCfg.getCostumeIndex(), shared)
+	} else if spriteCfg.Skeleton != nil && spriteCfg.CostumeSet == nil && spriteCfg.CostumeMPSet == nil {
+		p.baseObj.initWithSkeleton()
 	} else {
 		p.baseObj.initWith(base, spriteCfg, shared)
 	}
@@ -302,6 +343,34 @@ func (p *SpriteImpl) init(
 	p.rotationStyle = toRotationStyle(spriteCfg.RotationStyle)
 	p.isVisible = spriteCfg.Visible
 	p.pivot = spriteCfg.Pivot
+	// the pen is opaque blue and 1 pixel wide by default, as in Scratch
+	p.penParams = defaultPenParams
+	p.penShade = 50
+	p.penWidth = 1
+	p.updatePenColor()
+	p.SetRenderLayer(spriteCfg.RenderLayer)
+	if spriteCfg.Collider != nil {
+		for _, c := range p.costumes {
+			if c.collider == nil {
+				c.collider = spriteCfg.Collider
+			}
+		}
+	}
+	p.zLayer = g.zLayerIndex(spriteCfg.Layer)
+	p.collisionLayer, p.collisionMask = 1, ^uint32(0)
+	if spriteCfg.CollisionLayer != nil {
+		p.collisionLayer = *spriteCfg.CollisionLayer
+	}
+	if spriteCfg.CollisionMask != nil {
+		p.collisionMask = *spriteCfg.CollisionMask
+	}
+	p.body.init(spriteCfg.Body)
+	if spriteCfg.Skeleton != nil {
+		p.skeleton = newSpriteSkeleton(base, g.fs, spriteCfg.Skeleton)
+	}
+	if spriteCfg.Animator != nil {
+		p.animator = newAnimator(p, spriteCfg.Animator)
+	}
 
 	p.animBindings = make(map[string]string)
 	for key, val := range spriteCfg.AnimBindings {
@@ -394,6 +463,21 @@ func (p *SpriteImpl) init(
 		p.animations[key] = ani
 	}
 
+	if sheet != nil {
+		p.addAtlasAnimations(sheet, spriteCfg.CostumeAtlas.Fps)
+	}
+	if p.skeleton != nil {
+		p.addSkeletonAnimations()
+	}
+
+	for key, ani := range p.animations {
+		easing, ok := parseEasing(ani.Easing)
+		if !ok {
+			log.Printf("animation [%s] has an unknown easing: %s, using linear", key, ani.Easing)
+		}
+		ani.easing = easing
+	}
+
 	p.collider.others = make(map[*SpriteImpl]bool)
 	p.collider.sprite = p
 }
@@ -401,6 +485,36 @@ func (p *SpriteImpl) awake() {
 	p.playDefaultAnim()
 }
 
+// addAtlasAnimations adds the tags of a sprite sheet atlas as frame
+// animations, unless animations with the same names are defined. Frames
+// without a duration last 1/fps seconds.
+func (p *SpriteImpl) addAtlasAnimations(sheet *atlas.Atlas, fps float64) {
+	if fps <= 0 {
+		fps = 25
+	}
+	for _, tag := range sheet.Tags {
+		if _, ok := p.animations[tag.Name]; ok || len(tag.Frames) == 0 {
+			continue
+		}
+		frames := make([]aniFrame, len(tag.Frames))
+		total := 0
+		for i, idx := range tag.Frames {
+			ms := sheet.Frames[idx].Duration
+			if ms <= 0 {
+				ms = int(math.Max(math.Round(1000/fps), 1))
+			}
+			frames[i] = aniFrame{costume: idx, ms: ms}
+			total += ms
+		}
+		p.animations[tag.Name] = &aniConfig{
+			AniType: aniTypeFrame, Fps: 1000, FrameFps: 1000,
+			From: float64(tag.Frames[0]), To: float64(tag.Frames[len(tag.Frames)-1]),
+			Duration: float64(total) / 1000,
+			frames:   frames,
+		}
+	}
+}
+
 func (p *SpriteImpl) InitFrom(src *SpriteImpl) {
 	p.baseObj.initFrom(&src.baseObj)
 	p.eventSinks.initFrom(&src.eventSinks, p)
@@ -410,14 +524,22 @@ func (p *SpriteImpl) InitFrom(src *SpriteImpl) {
 	p.scale = src.scale
 	p.direction = src.direction
 	p.rotationStyle = src.rotationStyle
+	p.renderLayer = src.renderLayer
+	p.zLayer = src.zLayer
+	p.collisionLayer = src.collisionLayer
+	p.collisionMask = src.collisionMask
+	p.body = src.body
 	p.sayObj = nil
 	p.animations = src.animations
 	p.greffUniforms = cloneMap(src.greffUniforms)
 
 	p.penColor = src.penColor
+	p.penParams = src.penParams
 	p.penShade = src.penShade
-	p.penHue = src.penHue
 	p.penWidth = src.penWidth
+	p.penCap = src.penCap
+	p.penDash, p.penGap = src.penDash, src.penGap
+	p.penDashOffset = src.penDashOffset
 
 	p.isVisible = src.isVisible
 	p.isCloned_ = true
@@ -431,6 +553,18 @@ func (p *SpriteImpl) InitFrom(src *SpriteImpl) {
 	p.hasOnTouching = false
 	p.hasOnTouchEnd = false
 
+	p.playing = nil
+	if src.skeleton != nil {
+		p.skeleton = src.skeleton.clone()
+	}
+	if src.trail != nil {
+		p.trail = &spriteTrail{opts: src.trail.opts}
+	}
+	if src.animator != nil {
+		p.animator = new(Animator)
+		p.animator.initFrom(p, src.animator)
+	}
+
 	p.collider.others = make(map[*SpriteImpl]bool)
 	p.collider.sprite = p
 }
@@ -470,6 +604,15 @@ func applySpriteProps(dest *SpriteImpl, v specsp) {
 	if idx, ok := v["costumeIndex"]; ok {
 		dest.costumeIndex_ = int(idx.(float64))
 	}
+	if layer, ok := v["layer"].(string); ok {
+		dest.zLayer = dest.g.zLayerIndex(layer)
+	}
+	if layer, ok := v["collisionLayer"].(float64); ok {
+		dest.collisionLayer = uint32(layer)
+	}
+	if mask, ok := v["collisionMask"].(float64); ok {
+		dest.collisionMask = uint32(mask)
+	}
 	dest.isCloned_ = false
 }
 
@@ -734,6 +877,7 @@ func (p *SpriteImpl) Show() {
 		log.Println("Show", p.name)
 	}
 	p.isVisible = true
+	p.g.queryDirty = true
 }
 
 func (p *SpriteImpl) Visible() bool {
@@ -852,21 +996,28 @@ func (p *SpriteImpl) goAnimate(name SpriteAnimationName, ani *aniConfig) {
 	p.goAnimateInternal(name, ani, true)
 }
 func (p *SpriteImpl) goAnimateInternal(name SpriteAnimationName, ani *aniConfig, isBlocking bool) {
-	if p.lastAnim != nil {
-		p.isWaitingStopAnim = true
-		p.lastAnim.Stop()
-		p.isWaitingStopAnim = false
-	}
-
-	var animwg sync.WaitGroup
+	a := p.startAnimation(name, ani, "")
 	if isBlocking {
-		animwg.Add(1)
+		a.Wait()
+		if a.playDefault {
+			p.playDefaultAnim()
+		}
 	}
+}
 
-	if ani.OnStart != nil && ani.OnStart.Play != "" {
-		p.g.Play__3(ani.OnStart.Play)
+// startAnimation starts playing an animation on a channel, replacing the one
+// playing on it.
+func (p *SpriteImpl) startAnimation(name SpriteAnimationName, ani *aniConfig, channel string) *Animation {
+	if last := p.playing[channel]; last != nil {
+		last.replaced = true
+		last.an.Stop()
 	}
 
+	a := &Animation{sprite: p, name: name, channel: channel}
+	a.wg.Add(1)
+
+	p.doAction(ani.OnStart)
+
 	//anim frame
 	fromval, toval := p.getFromAnToForAni(ani.AniType, ani.From, ani.To)
 	frameFrom, frameTo := 0.0, 0.0
@@ -888,10 +1039,12 @@ func (p *SpriteImpl) goAnimateInternal(name SpriteAnimationName, ani *aniConfig,
 
 	if ani.AniType == aniTypeFrame {
 		p.goSetCostume(ani.From)
-		if ani.Fps == 0 { //compute fps
-			ani.Fps = math.Abs(tovalf-fromvalf+1) / ani.Duration
-		} else {
-			ani.Duration = math.Abs(tovalf-fromvalf+1) / ani.Fps
+		if ani.frames == nil { // else Duration is the sum of the frame durations
+			if ani.Fps == 0 { //compute fps
+				ani.Fps = math.Abs(tovalf-fromvalf+1) / ani.Duration
+			} else {
+				ani.Duration = math.Abs(tovalf-fromvalf+1) / ani.Fps
+			}
 		}
 	}
 
@@ -903,17 +1056,24 @@ func (p *SpriteImpl) goAnimateInternal(name SpriteAnimationName, ani *aniConfig,
 	pre_direction := p.direction //turn p.direction
 
 	an := anim.NewAnim(name, fps, framenum, ani.IsLoop)
+	an.SetEasingFunction(ani.easing.function())
 	// create channels
 	defaultChannel := []*anim.AnimationKeyFrame{{Frame: 0, Value: fromval}, {Frame: framenum - 1, Value: toval}}
 	switch ani.AniType {
 	case aniTypeFrame:
-		an.AddChannel(AnimChannelFrame, anim.AnimValTypeInt, defaultChannel)
+		if ani.frames != nil {
+			an.AddChannel(AnimChannelFrame, anim.AnimValTypeInt, frameKeys(ani.frames))
+		} else {
+			an.AddChannel(AnimChannelFrame, anim.AnimValTypeInt, defaultChannel)
+		}
 	case aniTypeMove:
 		an.AddChannel(AnimChannelMove, anim.AnimValTypeFloat, defaultChannel)
 	case aniTypeTurn:
 		an.AddChannel(AnimChannelTurn, anim.AnimValTypeFloat, defaultChannel)
 	case aniTypeGlide:
 		an.AddChannel(AnimChannelGlide, anim.AnimValTypeVector2, defaultChannel)
+	case aniTypeSkeleton:
+		an.AddChannel(AnimChannelSkeleton, anim.AnimValTypeFloat, defaultChannel)
 	}
 	if hasExtraChannel && ani.AniType != aniTypeFrame {
 		iFrameFrom := int(math.Round(frameFrom))
@@ -944,18 +1104,29 @@ func (p *SpriteImpl) goAnimateInternal(name SpriteAnimationName, ani *aniConfig,
 		an.AddChannel(AnimChannelFrame, anim.AnimValTypeInt, keyFrames)
 	}
 
-	p.lastAnim = an
+	a.an = an
+	if p.playing == nil {
+		p.playing = make(map[string]*Animation)
+	}
+	p.playing[channel] = a
 	if debugInstr {
 		log.Printf("New anim [name %s id %d] from:%v to:%v framenum:%d fps:%f", an.Name, an.Id, fromval, toval, framenum, fps)
 	}
+	lastFrame, endFrame := -1, ani.frameAt(framenum-1)
+	reachFrame := func(frame int) { // fires events of the frames passed since the last one
+		for f := lastFrame + 1; f <= frame; f++ {
+			p.doFrameEvents(name, ani, f)
+		}
+		lastFrame = frame
+	}
 	an.SetOnPlayingListener(func(currframe int, isReplay bool, progress float64) {
 		if debugInstr {
 			log.Printf("playing anim [name %s id %d]  currframe %d", an.Name, an.Id, currframe)
 		}
 		if isReplay && ani.IsLoop {
-			if ani.OnStart != nil && ani.OnStart.Play != "" {
-				p.g.Play__3(ani.OnStart.Play)
-			}
+			reachFrame(endFrame)
+			lastFrame = -1
+			p.doAction(ani.OnStart)
 		}
 		frameValue := an.SampleChannel(AnimChannelFrame)
 		if frameValue != nil {
@@ -965,8 +1136,20 @@ func (p *SpriteImpl) goAnimateInternal(name SpriteAnimationName, ani *aniConfig,
 		moveValue := an.SampleChannel(AnimChannelMove)
 		if moveValue != nil {
 			val, _ := tools.GetFloat(moveValue)
-			sin, cos := math.Sincos(toRadian(pre_direction))
-			p.doMoveToForAnim(pre_x+val*sin, pre_y+val*cos, an)
+			if ani.path != nil {
+				x, y, heading := ani.path.pointAt(val)
+				p.setDirection(heading, false)
+				p.doMoveToForAnim(x, y, an)
+			} else {
+				sin, cos := math.Sincos(toRadian(pre_direction))
+				p.doMoveToForAnim(pre_x+val*sin, pre_y+val*cos, an)
+			}
+		}
+		skeletonValue := an.SampleChannel(AnimChannelSkeleton)
+		if skeletonValue != nil {
+			val, _ := tools.GetFloat(skeletonValue)
+			p.skeleton.pose(name, val)
+			p.getDrawInfo().updateMatrix()
 		}
 		turnValue := an.SampleChannel(AnimChannelTurn)
 		if turnValue != nil {
@@ -980,6 +1163,7 @@ func (p *SpriteImpl) goAnimateInternal(name SpriteAnimationName, ani *aniConfig,
 				p.SetXYpos(val.X, val.Y)
 			}
 		}
+		reachFrame(ani.frameAt(currframe))
 		playaction := ani.OnPlay
 		if playaction != nil {
 			if ani.AniType != aniTypeFrame && playaction.Costumes != nil {
@@ -992,36 +1176,101 @@ func (p *SpriteImpl) goAnimateInternal(name SpriteAnimationName, ani *aniConfig,
 			}
 		}
 	})
-	isNeedPlayDefault := false
 	an.SetOnStopingListener(func() {
 		if debugInstr {
 			log.Printf("stop anim [name %s id %d]  ", an.Name, an.Id)
 		}
-		if isBlocking {
-			animwg.Done()
+		a.h.Stop()
+		if p.playing[channel] == a {
+			delete(p.playing, channel)
 		}
-		p.lastAnim = nil
-		if !p.isWaitingStopAnim && name != p.defaultAnimation && p.isVisible && !ani.IsKeepOnStop {
+		completed := !a.replaced && !a.stopped
+		if completed && channel == "" && name != p.defaultAnimation && p.isVisible && !ani.IsKeepOnStop {
 			dieAnimName := p.getStateAnimName(StateDie)
 			if name != dieAnimName {
-				isNeedPlayDefault = true
+				a.playDefault = true
 			}
 		}
+		a.wg.Done()
+		if completed {
+			p.doAction(ani.OnEnd)
+			p.doWhenAnimationEnd(p, name)
+		}
 	})
 
-	var h *tickHandler
-	h = p.g.startTick(-1, func(tick int64) {
-		runing := an.Update(1000.0 / p.g.currentTPS() * float64(tick))
-		if !runing {
-			h.Stop()
+	var pausedTicks int64
+	a.h = p.g.startTick(-1, func(tick int64) {
+		if a.h.self.Stopped() { // the script playing the animation is aborted
+			a.Stop()
+			return
+		}
+		if a.paused {
+			pausedTicks++
+		}
+		if !an.Update(1000.0 / p.g.currentTPS() * float64(tick-pausedTicks)) {
+			a.h.Stop()
 		}
 	})
-	if isBlocking {
-		waitToDo(animwg.Wait)
+	return a
+}
+
+// frameAt returns the frame of the animation at frame n of its channels. It
+// is n, unless the frames have their own durations.
+func (p *aniConfig) frameAt(n int) int {
+	if p.frames == nil {
+		return n
+	}
+	ms := 0
+	for i, f := range p.frames {
+		if ms += f.ms; n < ms {
+			return i
+		}
+	}
+	return len(p.frames) - 1
+}
+
+// doFrameEvents does the actions of an animation at a frame, and fires
+// OnAnimationFrame.
+func (p *SpriteImpl) doFrameEvents(name SpriteAnimationName, ani *aniConfig, frame int) {
+	for _, ev := range ani.Events {
+		if ev.Frame == frame {
+			p.doAction(&ev.actionConfig)
+		}
+	}
+	p.doWhenAnimationFrame(p, name, frame)
+}
+
+// doAction plays the sound, broadcasts the message and clones the sprite of
+// an action, if any.
+func (p *SpriteImpl) doAction(action *actionConfig) {
+	if action == nil {
+		return
+	}
+	if action.Play != "" {
+		p.g.Play__3(action.Play)
+	}
+	if action.Broadcast != "" {
+		p.g.doBroadcast(action.Broadcast, nil, false)
 	}
-	if isNeedPlayDefault {
-		p.playDefaultAnim()
+	if action.Clone {
+		Gopt_SpriteImpl_Clone__0(p.sprite)
+	}
+}
+
+// frameKeys returns the key frames of an animation with per-frame durations,
+// played at 1000 fps: each frame shows its costume for its duration.
+func frameKeys(frames []aniFrame) []*anim.AnimationKeyFrame {
+	keys := make([]*anim.AnimationKeyFrame, 0, len(frames)*2)
+	start := 0
+	for _, f := range frames {
+		end := start + f.ms
+		keys = append(keys, &anim.AnimationKeyFrame{Frame: start, Value: f.costume})
+		if end-1 > start {
+			keys = append(keys, &anim.AnimationKeyFrame{Frame: end - 1, Value: f.costume})
+		}
+		start = end
 	}
+	return keys
 }
 
 func (p *SpriteImpl) Animate(name SpriteAnimationName) {
@@ -1173,23 +1422,32 @@ func (p *SpriteImpl) Step__1(step float64, animation SpriteAnimationName) {
 		log.Println("Step", p.name, step)
 	}
 	if ani, ok := p.animations[animation]; ok {
-		anicopy := *ani
-		anicopy.From = 0
-		anicopy.To = step
-		anicopy.AniType = aniTypeMove
-		anicopy.Duration = math.Abs(step) * ani.StepDuration
-
-		p.goAnimate(animation, &anicopy)
+		p.goStepAnimate(animation, ani, step, math.Abs(step)*ani.StepDuration, nil)
 		return
 	}
 	p.goMoveForward(step)
 }
 
+// goStepAnimate plays the step animation ani, moving the sprite forward by
+// dist in secs seconds, or along path by dist if path isn't nil.
+func (p *SpriteImpl) goStepAnimate(name SpriteAnimationName, ani *aniConfig, dist, secs float64, path Path) {
+	anicopy := *ani
+	anicopy.From = 0
+	anicopy.To = dist
+	anicopy.AniType = aniTypeMove
+	anicopy.Duration = secs
+	anicopy.path = path
+	p.goAnimate(name, &anicopy)
+}
+
 func (p *SpriteImpl) Step__2(step int) {
 	p.Step__0(float64(step))
 }
 
 func (p *SpriteImpl) playDefaultAnim() {
+	if p.animator != nil && p.animator.active() {
+		return // the animator plays the animations of its states
+	}
 	animName := p.defaultAnimation
 	if p.isVisible {
 		isPlayAnim := false
@@ -1234,19 +1492,7 @@ func (p *SpriteImpl) Goto__2(obj specialObj) {
 }
 
 func (p *SpriteImpl) Glide__0(x, y float64, secs float64) {
-	if debugInstr {
-		log.Println("Glide", p.name, x, y, secs)
-	}
-	x0, y0 := p.getXY()
-	ani := &aniConfig{
-		Duration: secs,
-		Fps:      24.0,
-		From:     math32.NewVector2(x0, y0),
-		To:       math32.NewVector2(x, y),
-		AniType:  aniTypeGlide,
-	}
-	animName := p.getStateAnimName(StateGlide)
-	p.goAnimate(animName, ani)
+	p.Glide__5(x, y, secs, Linear)
 }
 
 func (p *SpriteImpl) goGlide(obj interface{}, secs float64) {
@@ -1273,6 +1519,24 @@ func (p *SpriteImpl) Glide__4(pos Pos, secs float64) {
 	p.goGlide(pos, secs)
 }
 
+// Glide__5 glides to (x, y) in secs seconds, using the specified easing.
+func (p *SpriteImpl) Glide__5(x, y float64, secs float64, easing Easing) {
+	if debugInstr {
+		log.Println("Glide", p.name, x, y, secs, easing)
+	}
+	x0, y0 := p.getXY()
+	ani := &aniConfig{
+		Duration: secs,
+		Fps:      24.0,
+		From:     math32.NewVector2(x0, y0),
+		To:       math32.NewVector2(x, y),
+		AniType:  aniTypeGlide,
+		easing:   easing,
+	}
+	animName := p.getStateAnimName(StateGlide)
+	p.goAnimate(animName, ani)
+}
+
 func (p *SpriteImpl) SetXYpos(x, y float64) {
 	p.doMoveTo(x, y)
 }
@@ -1332,6 +1596,20 @@ func (p *SpriteImpl) SetRotationStyle(style RotationStyle) {
 	p.rotationStyle = style
 }
 
+// SetRenderLayer sets the render layer (0..31) of the sprite. A camera only
+// shows the sprite if the layer is in its layer mask.
+func (p *SpriteImpl) SetRenderLayer(layer int) {
+	if layer < 0 || layer >= maxRenderLayers {
+		log.Println("SetRenderLayer: invalid layer -", layer)
+		return
+	}
+	p.renderLayer = layer
+}
+
+func (p *SpriteImpl) RenderLayer() int {
+	return p.renderLayer
+}
+
 func (p *SpriteImpl) Heading() float64 {
 	return p.direction
 }
@@ -1410,14 +1688,7 @@ func (p *SpriteImpl) turnTo(obj interface{}) {
 
 	animName := p.getStateAnimName(StateTurn)
 	if ani, ok := p.animations[animName]; ok {
-		fromangle := math.Mod(p.direction+360.0, 360.0)
-		toangle := math.Mod(angle+360.0, 360.0)
-		if toangle-fromangle > 180.0 {
-			fromangle = fromangle + 360.0
-		}
-		if fromangle-toangle > 180.0 {
-			toangle = toangle + 360.0
-		}
+		fromangle, toangle := p.turnAngles(angle)
 		delta := math.Abs(fromangle - toangle)
 		anicopy := *ani
 		anicopy.From = fromangle
@@ -1432,6 +1703,20 @@ func (p *SpriteImpl) turnTo(obj interface{}) {
 	}
 }
 
+// turnAngles returns the angles to animate from the current heading to
+// angle, in the shortest way.
+func (p *SpriteImpl) turnAngles(angle float64) (fromangle, toangle float64) {
+	fromangle = math.Mod(p.direction+360.0, 360.0)
+	toangle = math.Mod(angle+360.0, 360.0)
+	if toangle-fromangle > 180.0 {
+		fromangle = fromangle + 360.0
+	}
+	if fromangle-toangle > 180.0 {
+		toangle = toangle + 360.0
+	}
+	return
+}
+
 func (p *SpriteImpl) TurnTo__0(sprite Sprite) {
 	p.turnTo(sprite)
 }
@@ -1452,6 +1737,24 @@ func (p *SpriteImpl) TurnTo__4(obj specialObj) {
 	p.turnTo(obj)
 }
 
+// TurnTo__5 turns to degree in secs seconds, using the specified easing.
+func (p *SpriteImpl) TurnTo__5(degree float64, secs float64, easing Easing) {
+	if debugInstr {
+		log.Println("TurnTo", p.name, degree, secs, easing)
+	}
+	fromangle, toangle := p.turnAngles(degree)
+	ani := &aniConfig{
+		Duration: secs,
+		Fps:      24.0,
+		From:     fromangle,
+		To:       toangle,
+		AniType:  aniTypeTurn,
+		easing:   easing,
+	}
+	animName := p.getStateAnimName(StateTurn)
+	p.goAnimate(animName, ani)
+}
+
 func (p *SpriteImpl) SetHeading(dir float64) {
 	p.setDirection(dir, false)
 }
@@ -1560,6 +1863,7 @@ func (p *SpriteImpl) TouchingColor(color Color) bool {
 // Touching func:
 //
 //	Touching(sprite)
+//	Touching(tilemap)
 //	Touching(spx.Mouse)
 //	Touching(spx.Edge)
 //	Touching(spx.EdgeLeft)
@@ -1575,7 +1879,12 @@ func (p *SpriteImpl) touching(obj interface{}) bool {
 		if o := p.g.touchingSpriteBy(p, v); o != nil {
 			return true
 		}
+		if tm := p.g.findTilemap(v); tm != nil {
+			return tm.touchingSprite(p)
+		}
 		return false
+	case *Tilemap:
+		return v.touchingSprite(p)
 	case specialObj:
 		if v > 0 {
 			return p.checkTouchingScreen(int(v)) != 0
@@ -1601,6 +1910,10 @@ func (p *SpriteImpl) Touching__2(obj specialObj) bool {
 	return p.touching(obj)
 }
 
+func (p *SpriteImpl) Touching__3(tilemap *Tilemap) bool {
+	return p.touching(tilemap)
+}
+
 func touchingSprite(dst, src *SpriteImpl) bool {
 	if !src.isVisible || src.isDying {
 		return false
@@ -1624,6 +1937,9 @@ func (p *SpriteImpl) BounceOffEdge() {
 	dir := p.Heading()
 	where := checkTouchingDirection(dir)
 	touching := p.checkTouchingScreen(where)
+	if touching == 0 {
+		touching = p.g.touchingTiles(p, where)
+	}
 	if touching == 0 {
 		return
 	}
@@ -1721,8 +2037,14 @@ func (p *SpriteImpl) GotoBack() {
 
 // -----------------------------------------------------------------------------
 
+// Stamp draws the sprite onto the pen layer as it is now, with its graphic
+// effects.
 func (p *SpriteImpl) Stamp() {
-	p.g.stampCostume(p.getDrawInfo())
+	di := p.getDrawInfo()
+	if !di.visible {
+		return
+	}
+	p.g.stampCostume(&penStamp{draw: di.snapshot()})
 }
 
 func (p *SpriteImpl) PenUp() {
@@ -1733,15 +2055,68 @@ func (p *SpriteImpl) PenDown() {
 	p.isPenDown = true
 }
 
+// SetPenColor sets the pen color, and its color parameters to the ones of
+// color.
 func (p *SpriteImpl) SetPenColor(color Color) {
-	h, _, v := clrutil.RGB2HSV(color.R, color.G, color.B)
-	p.penHue = (200 * h) / 360
-	p.penShade = 50 * v
+	h, s, v := hsv.FromRGBf(float64(color.R)/0xff, float64(color.G)/0xff, float64(color.B)/0xff)
+	p.penParams = [numPenColorParams]float64{
+		ColorParam:        h * 100,
+		SaturationParam:   s * 100,
+		BrightnessParam:   v * 100,
+		TransparencyParam: (1 - float64(color.A)/0xff) * 100,
+	}
+	p.penShade = clrutil.SVToShade(s, v)
 	p.penColor = color
 }
 
+// ChangePenColor changes the color parameter of the pen, see
+// ChangePenColorParam.
 func (p *SpriteImpl) ChangePenColor(delta float64) {
-	panic("todo")
+	p.setPenColorParam(ColorParam, delta, true)
+}
+
+// SetPenColorParam sets a color parameter of the pen, from 0 to 100. The
+// color parameter wraps around, the others are clamped. Setting the saturation
+// or the brightness sets the shade of Scratch 2 closest to them.
+func (p *SpriteImpl) SetPenColorParam(param PenColorParam, value float64) {
+	p.setPenColorParam(param, value, false)
+}
+
+func (p *SpriteImpl) ChangePenColorParam(param PenColorParam, delta float64) {
+	p.setPenColorParam(param, delta, true)
+}
+
+func (p *SpriteImpl) setPenColorParam(param PenColorParam, v float64, change bool) {
+	if param < 0 || param >= numPenColorParams {
+		log.Println("SetPenColorParam: invalid param -", param)
+		return
+	}
+	if change {
+		v += p.penParams[param]
+	}
+	if param == ColorParam {
+		v = math.Mod(v, 100)
+		if v < 0 {
+			v += 100
+		}
+	} else {
+		v = math.Max(0, math.Min(v, 100))
+	}
+	p.penParams[param] = v
+	if param == SaturationParam || param == BrightnessParam {
+		p.penShade = clrutil.SVToShade(p.penParams[SaturationParam]/100, p.penParams[BrightnessParam]/100)
+	}
+	p.updatePenColor()
+}
+
+// updatePenColor computes the pen color from its color parameters.
+func (p *SpriteImpl) updatePenColor() {
+	params := &p.penParams
+	r, g, b := hsv.ToRGBf(params[ColorParam]/100, params[SaturationParam]/100, params[BrightnessParam]/100)
+	p.penColor = color.RGBA{
+		R: uint8(math.Round(r * 0xff)), G: uint8(math.Round(g * 0xff)), B: uint8(math.Round(b * 0xff)),
+		A: uint8(math.Round((1 - params[TransparencyParam]/100) * 0xff)),
+	}
 }
 
 func (p *SpriteImpl) SetPenShade(shade float64) {
@@ -1752,6 +2127,8 @@ func (p *SpriteImpl) ChangePenShade(delta float64) {
 	p.setPenShade(delta, true)
 }
 
+// SetPenHue sets the hue of Scratch 2, from 0 to 200, which is twice the
+// color parameter.
 func (p *SpriteImpl) SetPenHue(hue float64) {
 	p.setPenHue(hue, false)
 }
@@ -1762,16 +2139,18 @@ func (p *SpriteImpl) ChangePenHue(delta float64) {
 
 func (p *SpriteImpl) setPenHue(v float64, change bool) {
 	if change {
-		v += p.penHue
+		v += p.penParams[ColorParam] * 2
 	}
 	v = math.Mod(v, 200)
 	if v < 0 {
 		v += 200
 	}
-	p.penHue = v
-	p.doUpdatePenColor()
+	p.penParams[ColorParam] = v / 2
+	p.updatePenColor()
 }
 
+// setPenShade sets the shade of Scratch 2, which mixes the hue with black or
+// white by changing the saturation and brightness parameters.
 func (p *SpriteImpl) setPenShade(v float64, change bool) {
 	if change {
 		v += p.penShade
@@ -1781,21 +2160,26 @@ func (p *SpriteImpl) setPenShade(v float64, change bool) {
 		v += 200
 	}
 	p.penShade = v
-	p.doUpdatePenColor()
+	sat, bright := clrutil.ShadeToSV(v)
+	p.penParams[SaturationParam] = sat * 100
+	p.penParams[BrightnessParam] = bright * 100
+	p.updatePenColor()
 }
 
-func (p *SpriteImpl) doUpdatePenColor() {
-	r, g, b := clrutil.HSV2RGB((p.penHue*180)/100, 1, 1)
-	shade := p.penShade
-	if shade > 100 { // range 0..100
-		shade = 200 - shade
-	}
-	if shade < 50 {
-		r, g, b = clrutil.MixRGB(0, 0, 0, r, g, b, (10+shade)/60)
-	} else {
-		r, g, b = clrutil.MixRGB(r, g, b, 255, 255, 255, (shade-50)/60)
+// SetPenCap sets the shape of the ends of pen lines, PenCapRound by default.
+func (p *SpriteImpl) SetPenCap(cap PenCap) {
+	p.penCap = cap
+}
+
+// SetPenDash makes pen lines dashed, with dashes and gaps of the specified
+// lengths. A dash of 0 makes them solid.
+func (p *SpriteImpl) SetPenDash(dash, gap float64) {
+	if dash < 0 || gap < 0 {
+		log.Println("SetPenDash: invalid dash -", dash, gap)
+		return
 	}
-	p.penColor = color.RGBA{R: r, G: g, B: b, A: p.penColor.A}
+	p.penDash, p.penGap = dash, gap
+	p.penDashOffset = 0
 }
 
 func (p *SpriteImpl) SetPenSize(size float64) {
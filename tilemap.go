@@ -0,0 +1,395 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"image"
+	"log"
+	"math"
+
+	"github.com/goplus/spx/internal/math32"
+	"github.com/goplus/spx/internal/tiled"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// -------------------------------------------------------------------------------------
+
+// Tilemap is a map made of tiles, loaded from a Tiled map file (.tmj, .json
+// or .tmx). Non-empty tiles of its collision layer are solid: sprites touch
+// them (see SpriteImpl.Touching) and bounce off them (see BounceOffEdge).
+//
+//	{
+//	  "type": "tilemap",
+//	  "name": "level1",
+//	  "path": "maps/level1.tmj",
+//	  "collision": "walls",
+//	  "x": 0,
+//	  "y": 0
+//	}
+type Tilemap struct {
+	game      *Game
+	name      string
+	m         *tiled.Map
+	x, y      float64      // position of the map center, in stage coordinates
+	collision *tiled.Layer // solid tiles, may be nil
+	main      *tiled.Layer // layer of TileAt and SetTile without a layer name
+	images    map[*tiled.Tileset]*delayloadImage
+	tiles     map[uint32]*ebiten.Image // tile images, by global tile id
+	visible   bool
+}
+
+func newTilemap(g *Game, v specsp) (*Tilemap, error) {
+	path := v["path"].(string)
+	m, err := tiled.Load(g.fs.Open, path)
+	if err != nil {
+		log.Println("newTilemap:", err)
+		return nil, err
+	}
+	p := &Tilemap{
+		game:    g,
+		m:       m,
+		images:  make(map[*tiled.Tileset]*delayloadImage),
+		tiles:   make(map[uint32]*ebiten.Image),
+		visible: true,
+	}
+	p.name, _ = getSpcspVal(v, "name", "").(string)
+	p.x, _ = getSpcspVal(v, "x", 0.0).(float64)
+	p.y, _ = getSpcspVal(v, "y", 0.0).(float64)
+	if visible, ok := v["visible"].(bool); ok {
+		p.visible = visible
+	}
+	if name, _ := getSpcspVal(v, "collision", "").(string); name != "" {
+		if p.collision = m.Layer(name); p.collision == nil {
+			log.Println("newTilemap: collision layer not found -", name)
+		}
+	} else {
+		for _, layer := range m.Layers {
+			if layer.Properties["collision"] == true {
+				p.collision = layer
+				break
+			}
+		}
+	}
+	p.main = p.collision
+	if p.main == nil && len(m.Layers) > 0 {
+		p.main = m.Layers[len(m.Layers)-1]
+	}
+	for _, ts := range m.Tilesets {
+		p.images[ts] = &delayloadImage{loader: imageLoaderByPath(ts.Image)}
+	}
+	return p, nil
+}
+
+func (p *Tilemap) Name() string {
+	return p.name
+}
+
+func (p *Tilemap) Show() {
+	p.visible = true
+}
+
+func (p *Tilemap) Hide() {
+	p.visible = false
+}
+
+func (p *Tilemap) Visible() bool {
+	return p.visible
+}
+
+// Property returns the custom property name of the map.
+func (p *Tilemap) Property(name string) interface{} {
+	return p.m.Properties[name]
+}
+
+// TileProperty returns the custom property name of a tile.
+func (p *Tilemap) TileProperty(tile int, name string) interface{} {
+	return p.m.TileProperties(uint32(tile))[name]
+}
+
+func (p *Tilemap) layer(name string) *tiled.Layer {
+	layer := p.m.Layer(name)
+	if layer == nil {
+		log.Println("Tilemap: layer not found -", name)
+	}
+	return layer
+}
+
+func tileAt(layer *tiled.Layer, col, row int) int {
+	if layer == nil {
+		return 0
+	}
+	return int(tiled.GID(layer.Tile(col, row)))
+}
+
+// TileAt__0 returns the tile at (x, y) in the collision layer, or in the top
+// layer if the map has no collision layer. 0 means no tile.
+func (p *Tilemap) TileAt__0(x, y float64) int {
+	col, row := p.cellAt(x, y)
+	return tileAt(p.main, col, row)
+}
+
+// TileAt__1 returns the tile at (x, y) in the specified layer. 0 means no tile.
+func (p *Tilemap) TileAt__1(layer string, x, y float64) int {
+	col, row := p.cellAt(x, y)
+	return tileAt(p.layer(layer), col, row)
+}
+
+func (p *Tilemap) setTile(layer *tiled.Layer, x, y float64, tile int) {
+	if layer == nil {
+		return
+	}
+	col, row := p.cellAt(x, y)
+	layer.SetTile(col, row, uint32(tile))
+}
+
+// SetTile__0 sets the tile at (x, y) in the collision layer, or in the top layer
+// if the map has no collision layer. 0 clears the tile.
+func (p *Tilemap) SetTile__0(x, y float64, tile int) {
+	p.setTile(p.main, x, y, tile)
+}
+
+// SetTile__1 sets the tile at (x, y) in the specified layer. 0 clears the tile.
+func (p *Tilemap) SetTile__1(layer string, x, y float64, tile int) {
+	p.setTile(p.layer(layer), x, y, tile)
+}
+
+// Solid checks if there is a solid tile at (x, y).
+func (p *Tilemap) Solid(x, y float64) bool {
+	col, row := p.cellAt(x, y)
+	return tileAt(p.collision, col, row) != 0
+}
+
+func (p *Tilemap) size() (w, h float64) {
+	return float64(p.m.Width * p.m.TileWidth), float64(p.m.Height * p.m.TileHeight)
+}
+
+// cellAt returns the column and row of the cell at (x, y) in stage coordinates.
+func (p *Tilemap) cellAt(x, y float64) (col, row int) {
+	w, h := p.size()
+	col = int(math.Floor((x - p.x + w/2) / float64(p.m.TileWidth)))
+	row = int(math.Floor((p.y + h/2 - y) / float64(p.m.TileHeight)))
+	return
+}
+
+// cellRect returns the rect of a cell in stage coordinates.
+func (p *Tilemap) cellRect(col, row int) *math32.Rect {
+	w, h := p.size()
+	tw, th := float64(p.m.TileWidth), float64(p.m.TileHeight)
+	return math32.NewRect(p.x-w/2+float64(col)*tw, p.y+h/2-float64(row+1)*th, tw, th)
+}
+
+// touchingCells calls fn for each solid tile touched by sp until fn returns
+// true.
+func (p *Tilemap) touchingCells(sp *SpriteImpl, fn func(rc *math32.Rect) bool) bool {
+	if !p.visible || p.collision == nil {
+		return false
+	}
+	bound := sp.collisionBounds()
+	if bound == nil {
+		return false
+	}
+	col0, row0 := p.cellAt(bound.X, bound.Y+bound.Height)
+	col1, row1 := p.cellAt(bound.X+bound.Width, bound.Y)
+	for row := row0; row <= row1; row++ {
+		for col := col0; col <= col1; col++ {
+			if tileAt(p.collision, col, row) == 0 {
+				continue
+			}
+			rc := p.cellRect(col, row)
+			if sp.touchRotatedRect(math32.NewRotatedRect1(rc)) && fn(rc) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Tilemap) touchingSprite(sp *SpriteImpl) bool {
+	return p.touchingCells(sp, func(rc *math32.Rect) bool {
+		return true
+	})
+}
+
+// touchingSide returns on which side (touchingScreenLeft, etc.) sp touches
+// a solid tile, only considering the sides in where.
+func (p *Tilemap) touchingSide(sp *SpriteImpl, where int) (touching int) {
+	bound := sp.collisionBounds()
+	if bound == nil {
+		return
+	}
+	p.touchingCells(sp, func(rc *math32.Rect) bool {
+		overlap := bound.Intersect(rc)
+		var side int
+		if overlap.Width < overlap.Height {
+			side = touchingScreenLeft
+			if rc.X+rc.Width/2 > bound.X+bound.Width/2 {
+				side = touchingScreenRight
+			}
+		} else {
+			side = touchingScreenBottom
+			if rc.Y+rc.Height/2 > bound.Y+bound.Height/2 {
+				side = touchingScreenTop
+			}
+		}
+		if side&where != 0 {
+			touching = side
+			return true
+		}
+		return false
+	})
+	return
+}
+
+func (p *Tilemap) tileImage(gid uint32) *ebiten.Image {
+	if img, ok := p.tiles[gid]; ok {
+		return img
+	}
+	var img *ebiten.Image
+	if ts := p.m.Tileset(gid); ts != nil {
+		src := p.images[ts]
+		src.ensure(p.game.fs)
+		x, y, w, h := ts.TileRect(gid)
+		img = src.cache.Ebiten().SubImage(image.Rect(x, y, x+w, y+h)).(*ebiten.Image)
+	}
+	p.tiles[gid] = img
+	return img
+}
+
+func (p *Tilemap) draw(dc drawContext) {
+	if !p.visible {
+		return
+	}
+	worldW, worldH := p.game.worldSize_()
+	w, h := p.size()
+	tw, th := float64(p.m.TileWidth), float64(p.m.TileHeight)
+	left := float64(worldW)/2 + p.x - w/2 // map origin in world image pixels
+	top := float64(worldH)/2 - p.y - h/2
+	minX, minY, maxX, maxY := p.game.viewRect()
+
+	for _, layer := range p.m.Layers {
+		if !layer.Visible || layer.Opacity <= 0 {
+			continue
+		}
+		x0, y0 := left+layer.OffsetX, top+layer.OffsetY
+		col0, col1 := int(math.Floor((minX-x0)/tw))-1, int(math.Floor((maxX-x0)/tw))+1
+		row0, row1 := int(math.Floor((minY-y0)/th))-1, int(math.Floor((maxY-y0)/th))+1 // tiles may be higher than cells
+		row1 += p.maxTileRows()
+		if col0 < 0 {
+			col0 = 0
+		}
+		if row0 < 0 {
+			row0 = 0
+		}
+		if col1 >= layer.Width {
+			col1 = layer.Width - 1
+		}
+		if row1 >= layer.Height {
+			row1 = layer.Height - 1
+		}
+		for row := row0; row <= row1; row++ {
+			for col := col0; col <= col1; col++ {
+				tile := layer.Tile(col, row)
+				if tile == 0 {
+					continue
+				}
+				img := p.tileImage(tiled.GID(tile))
+				if img == nil {
+					continue
+				}
+				imgW, imgH := float64(img.Bounds().Dx()), float64(img.Bounds().Dy())
+				op := new(ebiten.DrawImageOptions)
+				applyTileFlips(&op.GeoM, tile, imgW, imgH)
+				// tiles are aligned to the bottom left corner of their cell
+				op.GeoM.Translate(x0+float64(col)*tw, y0+float64(row+1)*th-imgH)
+				op.ColorScale.ScaleAlpha(float32(layer.Opacity))
+				dc.DrawImage(img, op)
+			}
+		}
+	}
+}
+
+// maxTileRows returns how many rows the highest tile covers, minus 1.
+func (p *Tilemap) maxTileRows() int {
+	n := 0
+	for _, ts := range p.m.Tilesets {
+		if rows := (ts.TileHeight+p.m.TileHeight-1)/p.m.TileHeight - 1; rows > n {
+			n = rows
+		}
+	}
+	return n
+}
+
+func applyTileFlips(geo *ebiten.GeoM, tile uint32, w, h float64) {
+	if tile&(tiled.FlippedHorizontally|tiled.FlippedVertically|tiled.FlippedDiagonally) == 0 {
+		return
+	}
+	geo.Translate(-w/2, -h/2)
+	if tile&tiled.FlippedDiagonally != 0 { // swap x and y axes
+		var swap ebiten.GeoM
+		swap.SetElement(0, 0, 0)
+		swap.SetElement(0, 1, 1)
+		swap.SetElement(1, 0, 1)
+		swap.SetElement(1, 1, 0)
+		geo.Concat(swap)
+	}
+	if tile&tiled.FlippedHorizontally != 0 {
+		geo.Scale(-1, 1)
+	}
+	if tile&tiled.FlippedVertically != 0 {
+		geo.Scale(1, -1)
+	}
+	geo.Translate(w/2, h/2)
+}
+
+func (p *Tilemap) hit(hc hitContext) (hitResult, bool) {
+	return hitResult{}, false
+}
+
+// -------------------------------------------------------------------------------------
+
+// Tilemap returns the tilemap with the specified name, or nil if not found.
+func (p *Game) Tilemap(name string) *Tilemap {
+	if tm := p.findTilemap(name); tm != nil {
+		return tm
+	}
+	log.Println("Tilemap not found:", name)
+	return nil
+}
+
+func (p *Game) findTilemap(name string) *Tilemap {
+	for _, item := range p.items {
+		if tm, ok := item.(*Tilemap); ok && tm.name == name {
+			return tm
+		}
+	}
+	return nil
+}
+
+// touchingTiles returns on which side (touchingScreenLeft, etc.) sp touches a
+// solid tile of any tilemap, only considering the sides in where.
+func (p *Game) touchingTiles(sp *SpriteImpl, where int) int {
+	for _, item := range p.items {
+		if tm, ok := item.(*Tilemap); ok {
+			if touching := tm.touchingSide(sp, where); touching != 0 {
+				return touching
+			}
+		}
+	}
+	return 0
+}
+
+// -------------------------------------------------------------------------------------
@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"log"
+	"math"
+
+	"github.com/goplus/spx/internal/coroutine"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// -------------------------------------------------------------------------------------
+
+const (
+	defaultTrailLength = 0.5
+	defaultTrailWidth  = 4
+	maxTrailPoints     = 0x8000 // vertices are indexed by uint16
+)
+
+// TrailOpts are the options of SpriteImpl.SetTrail.
+type TrailOpts struct {
+	Length float64 // seconds of movement shown by the trail, 0.5 by default
+	Width  float64 // 4 by default
+	Fade   bool    // fade out and narrow to the tail
+	Color  Color   // the pen color if transparent
+}
+
+type trailPoint struct {
+	x, y float64 // in stage coordinates
+	time float64
+}
+
+// spriteTrail is a trail following a sprite, which isn't drawn on the pen
+// layer: its tail disappears after Length seconds.
+type spriteTrail struct {
+	opts   TrailOpts
+	points []trailPoint // from the tail to the head
+	time   float64      // seconds since the trail is set
+}
+
+func (p *spriteTrail) update(x, y, dt float64) {
+	p.time += dt
+	pts := p.points
+	i := 0
+	for i < len(pts) && pts[i].time < p.time-p.opts.Length {
+		i++
+	}
+	if n := len(pts) - i; n >= maxTrailPoints {
+		i += n - maxTrailPoints + 1
+	}
+	pts = pts[i:]
+	if n := len(pts); n == 0 || pts[n-1].x != x || pts[n-1].y != y {
+		pts = append(pts, trailPoint{x, y, p.time})
+	}
+	p.points = pts
+}
+
+// draw draws the trail as a strip of quads between points, whose alpha and
+// width fade to the tail if opts.Fade is set.
+func (p *spriteTrail) draw(dc drawContext, clr Color, worldW, worldH int) {
+	pts := p.points
+	if len(pts) < 2 || clr.A == 0 {
+		return
+	}
+	ox, oy := float64(worldW)/2, float64(worldH)/2
+	vs := make([]ebiten.Vertex, 0, len(pts)*2)
+	is := make([]uint16, 0, (len(pts)-1)*6)
+	for i, pt := range pts {
+		// the normal of the strip at pt, averaging adjacent segments
+		prev, next := pt, pt
+		if i > 0 {
+			prev = pts[i-1]
+		}
+		if i+1 < len(pts) {
+			next = pts[i+1]
+		}
+		dx, dy := next.x-prev.x, next.y-prev.y
+		l := math.Hypot(dx, dy)
+		if l == 0 {
+			dx, dy, l = 1, 0, 1
+		}
+		k := 1.0
+		if p.opts.Fade {
+			k = 1 - (p.time-pt.time)/p.opts.Length
+		}
+		hw := p.opts.Width * k / 2
+		nx, ny := -dy/l*hw, dx/l*hw
+		x, y := ox+pt.x, oy-pt.y
+		vs = append(vs,
+			solidVertex(float32(x+nx), float32(y-ny), clr, float32(k)),
+			solidVertex(float32(x-nx), float32(y+ny), clr, float32(k)),
+		)
+		if i > 0 {
+			base := uint16(i*2 - 2)
+			is = append(is, base, base+1, base+2, base+1, base+3, base+2)
+		}
+	}
+	op := &ebiten.DrawTrianglesOptions{AntiAlias: true}
+	dc.DrawTriangles(vs, is, whiteSubImage, op)
+}
+
+// -------------------------------------------------------------------------------------
+
+// SetTrail makes a trail follow the sprite, drawn behind it. Unlike pen
+// lines, the trail disappears after opts.Length seconds. SetTrail(nil) removes
+// the trail.
+func (p *SpriteImpl) SetTrail(opts *TrailOpts) {
+	if opts == nil {
+		p.trail = nil
+		return
+	}
+	if opts.Length < 0 || opts.Width < 0 {
+		log.Println("SetTrail: invalid options -", opts.Length, opts.Width)
+		return
+	}
+	t := &spriteTrail{opts: *opts}
+	if t.opts.Length == 0 {
+		t.opts.Length = defaultTrailLength
+	}
+	if t.opts.Width == 0 {
+		t.opts.Width = defaultTrailWidth
+	}
+	p.trail = t
+}
+
+func (p *SpriteImpl) drawTrail(dc drawContext, t *spriteTrail) {
+	clr := t.opts.Color
+	if clr.A == 0 {
+		clr = p.penColor
+	}
+	worldW, worldH := p.g.worldSize_()
+	t.draw(dc, clr, worldW, worldH)
+}
+
+func (p *Game) updateTrails() {
+	dt := 1 / p.currentTPS()
+	gco.CreateAndStart(true, nil, func(me coroutine.Thread) int {
+		for _, item := range p.items {
+			if sp, ok := item.(*SpriteImpl); ok {
+				if t := sp.trail; t != nil {
+					t.update(sp.x, sp.y, dt)
+				}
+			}
+		}
+		return 0
+	})
+}
+
+// -------------------------------------------------------------------------------------
@@ -0,0 +1,289 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"log"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/goplus/spx/internal/anim"
+)
+
+// -------------------------------------------------------------------------------------
+
+const (
+	tweenFps         = 60.0
+	animChannelTween = "@tween"
+	tweenPropX       = "x"
+	tweenPropY       = "y"
+	tweenPropHeading = "heading"
+	tweenPropSize    = "size"
+	tweenPropPenSize = "penSize"
+	tweenPropVolume  = "volume"
+)
+
+// Tween animates a property from its current value to a target value. A tween
+// is either a single property tween made by SpriteImpl.NewTween, or a sequence
+// or a parallel group of tweens.
+type Tween struct {
+	g      *Game
+	sprite *SpriteImpl
+
+	// property tween
+	get    func() float64
+	set    func(v float64)
+	to     float64
+	secs   float64
+	easing Easing
+	turn   bool // the property is a heading, turn the shortest way
+	an     *anim.Anim
+	done   bool
+
+	// group of tweens
+	children []*Tween
+	parallel bool
+
+	h  *tickHandler
+	wg *sync.WaitGroup
+}
+
+// tweenProperty returns the accessors of a tweenable property of p.
+func (p *SpriteImpl) tweenProperty(property string) (get func() float64, set func(v float64), ok bool) {
+	switch property {
+	case tweenPropX:
+		return p.Xpos, p.SetXpos, true
+	case tweenPropY:
+		return p.Ypos, p.SetYpos, true
+	case tweenPropHeading:
+		return p.Heading, func(v float64) { p.setDirection(v, false) }, true
+	case tweenPropSize:
+		return p.Size, p.SetSize, true
+	case tweenPropPenSize:
+		return func() float64 { return p.penWidth }, func(v float64) { p.setPenWidth(v, false) }, true
+	case tweenPropVolume:
+		return p.g.Volume, p.g.SetVolume, true
+	}
+	for i, name := range greffNames {
+		if strings.EqualFold(property, name) {
+			kind := EffectKind(i)
+			get = func() float64 {
+				if v, ok := p.greffUniforms[name]; ok {
+					return float64(v.(float32))
+				}
+				return 0
+			}
+			return get, func(v float64) { p.SetEffect(kind, v) }, true
+		}
+	}
+	return nil, nil, false
+}
+
+// NewTween creates a tween of a property of the sprite, without playing it.
+// The property is one of "x", "y", "heading", "size", "penSize", "volume" or
+// the name of an EffectKind, such as "ghost". The tween starts from the value
+// of the property when it begins to play, so tweens in a sequence can animate
+// the same property one after another. The tween of an unknown property
+// does nothing.
+func (p *SpriteImpl) NewTween(property string, to, secs float64, easing Easing) *Tween {
+	get, set, ok := p.tweenProperty(property)
+	if !ok {
+		log.Println("NewTween: unknown property -", property)
+		return p.newTweenGroup(nil, false) // an empty group, which ends at once
+	}
+	return &Tween{
+		g: p.g, sprite: p, get: get, set: set, to: to, secs: secs, easing: easing,
+		turn: property == tweenPropHeading,
+	}
+}
+
+// NewTweenVar creates a tween of the variable v, without playing it.
+func (p *SpriteImpl) NewTweenVar(v *float64, to, secs float64, easing Easing) *Tween {
+	return &Tween{
+		g: p.g, sprite: p, to: to, secs: secs, easing: easing,
+		get: func() float64 { return *v },
+		set: func(val float64) { *v = val },
+	}
+}
+
+// TweenSequence creates a tween playing tweens one after another.
+func (p *SpriteImpl) TweenSequence(tweens ...*Tween) *Tween {
+	return p.newTweenGroup(tweens, false)
+}
+
+// TweenParallel creates a tween playing tweens at the same time. It ends when
+// all of them end.
+func (p *SpriteImpl) TweenParallel(tweens ...*Tween) *Tween {
+	return p.newTweenGroup(tweens, true)
+}
+
+func (p *SpriteImpl) newTweenGroup(tweens []*Tween, parallel bool) *Tween {
+	children := make([]*Tween, 0, len(tweens))
+	for _, t := range tweens {
+		if t != nil {
+			children = append(children, t)
+		}
+	}
+	return &Tween{g: p.g, sprite: p, children: children, parallel: parallel}
+}
+
+// Tween animates property to the value to in secs seconds, using the
+// specified easing. It blocks until the tween is done. See NewTween for the
+// properties supported.
+func (p *SpriteImpl) Tween(property string, to, secs float64, easing Easing) {
+	if debugInstr {
+		log.Println("Tween", p.name, property, to, secs, easing)
+	}
+	p.NewTween(property, to, secs, easing).Play().Wait()
+}
+
+// Play starts playing the tween without blocking, and returns the tween. If
+// the tween is playing, it restarts. The tween stops where it is if the
+// script playing it is aborted.
+func (t *Tween) Play() *Tween {
+	t.Stop()
+	t.reset()
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	t.wg = wg
+	var h *tickHandler
+	h = t.g.startTick(-1, func(tick int64) {
+		if h.self.Stopped() {
+			if t.h == h {
+				t.end()
+			}
+			return
+		}
+		if !t.update(1000.0 / t.g.currentTPS() * float64(tick)) {
+			t.end()
+		}
+	})
+	t.h = h
+	return t
+}
+
+// Stop stops the tween, leaving the properties at their current values.
+func (t *Tween) Stop() {
+	if t.h != nil {
+		t.end()
+	}
+}
+
+func (t *Tween) end() {
+	t.h.Stop()
+	t.h = nil
+	t.wg.Done()
+}
+
+// Playing checks if the tween is playing.
+func (t *Tween) Playing() bool {
+	return t.h != nil
+}
+
+// Wait blocks until the tween ends or is stopped.
+func (t *Tween) Wait() {
+	if wg := t.wg; wg != nil {
+		waitToDo(wg.Wait)
+	}
+}
+
+func (t *Tween) reset() {
+	t.an, t.done = nil, false
+	for _, c := range t.children {
+		c.reset()
+	}
+}
+
+// duration returns the duration of the tween in milliseconds.
+func (t *Tween) duration() float64 {
+	if t.children == nil {
+		if t.secs <= 0 {
+			return 0
+		}
+		return float64(tweenFrames(t.secs)) * 1000 / tweenFps
+	}
+	d := 0.0
+	for _, c := range t.children {
+		if t.parallel {
+			d = math.Max(d, c.duration())
+		} else {
+			d += c.duration()
+		}
+	}
+	return d
+}
+
+func tweenFrames(secs float64) int {
+	return int(math.Max(secs*tweenFps, 2))
+}
+
+// update moves the tween to ms milliseconds after it started, and returns
+// false if the tween is done.
+func (t *Tween) update(ms float64) bool {
+	if t.children != nil {
+		if t.parallel {
+			running := false
+			for _, c := range t.children {
+				if c.update(ms) {
+					running = true
+				}
+			}
+			return running
+		}
+		start := 0.0
+		for _, c := range t.children {
+			if c.update(ms - start) {
+				return true
+			}
+			start += c.duration()
+		}
+		return false
+	}
+	if t.done {
+		return false
+	}
+	if t.an == nil {
+		if t.secs <= 0 {
+			t.set(t.to)
+			t.done = true
+			return false
+		}
+		from, to := t.get(), t.to
+		if t.turn {
+			from, to = t.sprite.turnAngles(to)
+		}
+		framenum := tweenFrames(t.secs)
+		an := anim.NewAnim("tween", tweenFps, framenum, false)
+		an.SetEasingFunction(t.easing.function())
+		an.AddChannel(animChannelTween, anim.AnimValTypeFloat, []*anim.AnimationKeyFrame{
+			{Frame: 0, Value: from},
+			{Frame: framenum - 1, Value: to},
+		})
+		t.an = an
+	}
+	running := t.an.Update(ms)
+	if v, ok := t.an.SampleChannel(animChannelTween).(float64); ok {
+		t.set(v)
+	}
+	if !running {
+		t.done = true
+	}
+	return running
+}
+
+// -------------------------------------------------------------------------------------
@@ -0,0 +1,834 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goplus/spx/internal/coroutine"
+	"github.com/goplus/spx/internal/gdi"
+	"github.com/goplus/spx/internal/math32"
+	"github.com/goplus/spx/internal/tools"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// -------------------------------------------------------------------------------------
+
+var (
+	uiBackground  = Color{R: 0xf6, G: 0xf8, B: 0xfa, A: 0xff}
+	uiBorder      = Color{R: 0xa0, G: 0xa8, B: 0xb0, A: 0xff}
+	uiAccent      = Color{R: 0x21, G: 0x9f, B: 0xfc, A: 0xff}
+	uiText        = Color{R: 0x00, G: 0x00, B: 0x00, A: 0xff}
+	uiPlaceholder = Color{R: 0x90, G: 0x90, B: 0x90, A: 0xff}
+)
+
+const (
+	uiFontSize    = 12
+	uiTextPadding = 4
+	uiBorderWidth = 1
+)
+
+type anchorKind int
+
+const (
+	anchorNone anchorKind = iota
+	anchorTopLeft
+	anchorTop
+	anchorTopRight
+	anchorLeft
+	anchorCenter
+	anchorRight
+	anchorBottomLeft
+	anchorBottom
+	anchorBottomRight
+)
+
+func toAnchor(anchor string) anchorKind {
+	switch anchor {
+	case "topLeft":
+		return anchorTopLeft
+	case "top":
+		return anchorTop
+	case "topRight":
+		return anchorTopRight
+	case "left":
+		return anchorLeft
+	case "center":
+		return anchorCenter
+	case "right":
+		return anchorRight
+	case "bottomLeft":
+		return anchorBottomLeft
+	case "bottom":
+		return anchorBottom
+	case "bottomRight":
+		return anchorBottomRight
+	}
+	return anchorNone
+}
+
+// hudShape is a shape which is drawn in screen space, on top of the world
+// rendered by the camera.
+type hudShape interface {
+	isHUD() bool
+}
+
+// layouter is a shape which needs to know the window size to place itself.
+type layouter interface {
+	layout(winW, winH int)
+}
+
+// widgetBase implements the common part of Widget: name, visibility, position
+// and size.
+//
+// Without an anchor, position (x, y) is the top-left corner of the widget in
+// stage coordinates (origin at the center of the window, y axis pointing up),
+// and the widget moves with the world when the camera pans.
+//
+// With an anchor, the widget is a HUD element drawn in screen space. Its
+// position is relative to the anchor point of the window: x and y are margins
+// from the anchored edges (positive values point inward), or offsets from the
+// center (y axis pointing up) for centered axes.
+type widgetBase struct {
+	game    *Game
+	name    WidgetName
+	x, y    float64
+	width   float64
+	height  float64
+	size    float64
+	visible bool
+
+	anchor     anchorKind
+	winW, winH float64 // window size of the last layout
+}
+
+func (p *widgetBase) initWith(g *Game, v specsp, width, height float64) {
+	p.game = g
+	p.name, _ = getSpcspVal(v, "name", "").(string)
+	p.x, _ = getSpcspVal(v, "x", 0.0).(float64)
+	p.y, _ = getSpcspVal(v, "y", 0.0).(float64)
+	p.width, _ = getSpcspVal(v, "width", width).(float64)
+	p.height, _ = getSpcspVal(v, "height", height).(float64)
+	p.size = 1.0
+	if v["size"] != nil {
+		if size, ok := tools.GetFloat(v["size"]); ok {
+			p.size = size
+		}
+	}
+	p.visible, _ = getSpcspVal(v, "visible", true).(bool)
+	anchor, _ := getSpcspVal(v, "anchor", "").(string)
+	p.anchor = toAnchor(anchor)
+}
+
+func (p *widgetBase) isHUD() bool {
+	return p.anchor != anchorNone
+}
+
+func (p *widgetBase) layout(winW, winH int) {
+	p.winW, p.winH = float64(winW), float64(winH)
+}
+
+// screenRect returns the rectangle of the widget in the pixel space of the
+// image it is drawn on: the world image, or the screen for HUD widgets.
+func (p *widgetBase) screenRect() (x, y, w, h float64) {
+	w, h = p.width*p.size, p.height*p.size
+	if p.anchor == anchorNone {
+		x, y = p.game.convertWinSpace2GameSpace(p.x, p.y)
+		return
+	}
+	if p.winW == 0 {
+		p.layout(p.game.windowSize_())
+	}
+	switch (p.anchor - anchorTopLeft) % 3 {
+	case 0:
+		x = p.x
+	case 1:
+		x = (p.winW-w)/2 + p.x
+	default:
+		x = p.winW - w - p.x
+	}
+	switch (p.anchor - anchorTopLeft) / 3 {
+	case 0:
+		y = p.y
+	case 1:
+		y = (p.winH-h)/2 - p.y
+	default:
+		y = p.winH - h - p.y
+	}
+	return
+}
+
+func (p *widgetBase) hitTest(hc hitContext) bool {
+	if !p.visible {
+		return false
+	}
+	pos := math32.NewVector2(float64(hc.Pos.X), float64(hc.Pos.Y))
+	if !p.isHUD() {
+		var cam *Camera
+		if cam, pos = p.game.cameraAt(hc.Pos.X, hc.Pos.Y); !cam.showsLayer(0) {
+			return false
+		}
+	}
+	x, y, w, h := p.screenRect()
+	return pos.X >= x && pos.X < x+w && pos.Y >= y && pos.Y < y+h
+}
+
+func (p *widgetBase) fontSize() int {
+	return int(uiFontSize*p.size + 0.5)
+}
+
+// -------------------------------------------------------------------------------------
+// IWidget
+
+func (pself *widgetBase) GetName() WidgetName {
+	return pself.name
+}
+
+func (pself *widgetBase) Visible() bool {
+	return pself.visible
+}
+func (pself *widgetBase) Show() {
+	pself.visible = true
+}
+func (pself *widgetBase) Hide() {
+	pself.visible = false
+}
+func (pself *widgetBase) Xpos() float64 {
+	return pself.x
+}
+func (pself *widgetBase) Ypos() float64 {
+	return pself.y
+}
+func (pself *widgetBase) SetXpos(x float64) {
+	pself.x = x
+}
+func (pself *widgetBase) SetYpos(y float64) {
+	pself.y = y
+}
+func (pself *widgetBase) SetXYpos(x float64, y float64) {
+	pself.x, pself.y = x, y
+}
+func (pself *widgetBase) ChangeXpos(dx float64) {
+	pself.x += dx
+}
+func (pself *widgetBase) ChangeYpos(dy float64) {
+	pself.y += dy
+}
+func (pself *widgetBase) ChangeXYpos(dx float64, dy float64) {
+	pself.x += dx
+	pself.y += dy
+}
+
+func (pself *widgetBase) Size() float64 {
+	return pself.size
+}
+func (pself *widgetBase) SetSize(size float64) {
+	pself.size = size
+}
+func (pself *widgetBase) ChangeSize(delta float64) {
+	pself.size += delta
+}
+
+// Width returns the unscaled width of the widget.
+func (pself *widgetBase) Width() float64 {
+	return pself.width
+}
+
+// Height returns the unscaled height of the widget.
+func (pself *widgetBase) Height() float64 {
+	return pself.height
+}
+
+// SetWidgetSize resizes the widget to the given unscaled width and height.
+func (pself *widgetBase) SetWidgetSize(width, height float64) {
+	pself.width, pself.height = width, height
+}
+
+// -------------------------------------------------------------------------------------
+
+func fillRect(dc drawContext, x, y, w, h float64, clr color.Color) {
+	rc := image.Rect(int(x), int(y), int(math.Ceil(x+w)), int(math.Ceil(y+h)))
+	if rc.Empty() {
+		return
+	}
+	dc.SubImage(rc).(*ebiten.Image).Fill(clr)
+}
+
+func strokeRect(dc drawContext, x, y, w, h, lineWidth float64, clr color.Color) {
+	fillRect(dc, x, y, w, lineWidth, clr)
+	fillRect(dc, x, y+h-lineWidth, w, lineWidth, clr)
+	fillRect(dc, x, y, lineWidth, h, clr)
+	fillRect(dc, x+w-lineWidth, y, lineWidth, h, clr)
+}
+
+// drawTextIn draws text vertically centered in the given rectangle. If center
+// is true, the text is horizontally centered too, otherwise it is left aligned.
+func drawTextIn(dc drawContext, font gdi.Font, text string, x, y, w, h float64, clr color.Color, center bool) {
+	if text == "" {
+		return
+	}
+	render := gdi.NewTextRender(font, 0x80000, 0)
+	render.AddText(text)
+	textW, textH := render.Size()
+	tx := x + uiTextPadding
+	if center {
+		tx = x + (w-float64(textW))/2
+	}
+	ty := y + (h-float64(textH))/2
+	render.Draw(dc.Image, int(tx), int(ty), clr, 0)
+}
+
+func parseColorOr(v specsp, key string, def Color) Color {
+	if c, err := parseColor(getSpcspVal(v, key)); err == nil {
+		return c
+	}
+	return def
+}
+
+// -------------------------------------------------------------------------------------
+
+// Button class.
+type Button struct {
+	widgetBase
+	text      string
+	color     Color
+	textColor Color
+}
+
+/*
+"type": "button",
+"name": "start",
+"text": "Start",
+"x": -50,
+"y": 20,
+"width": 100,
+"height": 30,
+"color": "#219ffc",
+"textColor": "white",
+"visible": true
+*/
+func newButton(g *Game, v specsp) *Button {
+	p := &Button{}
+	p.initWith(g, v, 80, 24)
+	p.text, _ = getSpcspVal(v, "text", "").(string)
+	p.color = parseColorOr(v, "color", uiAccent)
+	p.textColor = parseColorOr(v, "textColor", Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	return p
+}
+
+func (p *Button) draw(dc drawContext) {
+	if !p.visible {
+		return
+	}
+	x, y, w, h := p.screenRect()
+	fillRect(dc, x, y, w, h, p.color)
+	drawTextIn(dc, getOrCreateFont(p.fontSize()), p.text, x, y, w, h, p.textColor, true)
+}
+
+func (p *Button) hit(hc hitContext) (hr hitResult, ok bool) {
+	if p.hitTest(hc) {
+		return hitResult{Target: p}, true
+	}
+	return
+}
+
+func (p *Button) doWhenClick(this threadObj) {
+	p.game.sinkMgr.doWhenClick(this)
+}
+
+// OnClick registers a callback which is called when the button is clicked.
+func (p *Button) OnClick(onClick func()) {
+	mgr := &p.game.sinkMgr
+	mgr.allWhenClick = &eventSink{
+		prev:  mgr.allWhenClick,
+		pthis: p.game,
+		sink:  onClick,
+		cond: func(data interface{}) bool {
+			return data == p
+		},
+	}
+}
+
+func (p *Button) Text() string {
+	return p.text
+}
+
+func (p *Button) SetText(text string) {
+	p.text = text
+}
+
+// -------------------------------------------------------------------------------------
+
+// Label class.
+type Label struct {
+	widgetBase
+	text   string
+	color  Color
+	center bool
+}
+
+/*
+"type": "label",
+"name": "title",
+"text": "Hello",
+"x": -100,
+"y": 100,
+"color": "black",
+"align": "center"
+*/
+func newLabel(g *Game, v specsp) *Label {
+	p := &Label{}
+	p.initWith(g, v, 0, 0)
+	p.text, _ = getSpcspVal(v, "text", "").(string)
+	p.color = parseColorOr(v, "color", uiText)
+	align, _ := getSpcspVal(v, "align", "").(string)
+	p.center = align == "center"
+	return p
+}
+
+func (p *Label) draw(dc drawContext) {
+	if !p.visible || p.text == "" {
+		return
+	}
+	x, y, w, h := p.screenRect()
+	font := getOrCreateFont(p.fontSize())
+	if w == 0 || h == 0 { // auto size
+		render := gdi.NewTextRender(font, 0x80000, 0)
+		render.AddText(p.text)
+		textW, textH := render.Size()
+		render.Draw(dc.Image, int(x), int(y), p.color, 0)
+		p.width, p.height = float64(textW)/p.size, float64(textH)/p.size
+		return
+	}
+	drawTextIn(dc, font, p.text, x, y, w, h, p.color, p.center)
+}
+
+func (p *Label) hit(hc hitContext) (hr hitResult, ok bool) {
+	return
+}
+
+func (p *Label) Text() string {
+	return p.text
+}
+
+func (p *Label) SetText(text string) {
+	p.text = text
+}
+
+func (p *Label) SetColor(clr Color) {
+	p.color = clr
+}
+
+// -------------------------------------------------------------------------------------
+
+// Panel class, which draws an image (or a solid color if there is no image).
+type Panel struct {
+	widgetBase
+	img   *delayloadImage
+	color Color
+}
+
+/*
+"type": "panel",
+"name": "bg",
+"path": "images/panel.png",
+"color": "#f6f8fa",
+"x": -160,
+"y": 120,
+"width": 320,
+"height": 240
+*/
+func newPanel(g *Game, v specsp) *Panel {
+	p := &Panel{}
+	p.initWith(g, v, 0, 0)
+	p.color = parseColorOr(v, "color", uiBackground)
+	if path, ok := getSpcspVal(v, "path", "").(string); ok && path != "" {
+		p.img = &delayloadImage{loader: imageLoaderByPath(path)}
+	}
+	return p
+}
+
+func (p *Panel) draw(dc drawContext) {
+	if !p.visible {
+		return
+	}
+	x, y, w, h := p.screenRect()
+	if p.img == nil {
+		fillRect(dc, x, y, w, h, p.color)
+		return
+	}
+	p.img.ensure(p.game.fs)
+	img := p.img.cache.Ebiten()
+	imgW, imgH := img.Bounds().Dx(), img.Bounds().Dy()
+	if w == 0 || h == 0 { // use image size
+		w, h = float64(imgW)*p.size, float64(imgH)*p.size
+	}
+	op := new(ebiten.DrawImageOptions)
+	op.Filter = ebiten.FilterLinear
+	op.GeoM.Scale(w/float64(imgW), h/float64(imgH))
+	op.GeoM.Translate(x, y)
+	dc.DrawImage(img, op)
+}
+
+func (p *Panel) hit(hc hitContext) (hr hitResult, ok bool) {
+	return
+}
+
+// -------------------------------------------------------------------------------------
+
+// TextField class, a single line text input.
+type TextField struct {
+	widgetBase
+	text        string
+	placeholder string
+	maxLength   int
+	focused     bool
+	onChange    *eventSink
+	onSubmit    *eventSink
+}
+
+/*
+"type": "textField",
+"name": "username",
+"placeholder": "Your name",
+"maxLength": 16,
+"x": -80,
+"y": 0,
+"width": 160,
+"height": 24
+*/
+func newTextField(g *Game, v specsp) *TextField {
+	p := &TextField{}
+	p.initWith(g, v, 120, 24)
+	p.text, _ = getSpcspVal(v, "text", "").(string)
+	p.placeholder, _ = getSpcspVal(v, "placeholder", "").(string)
+	if n, ok := getSpcspVal(v, "maxLength", 0.0).(float64); ok {
+		p.maxLength = int(n)
+	}
+	return p
+}
+
+func (p *TextField) draw(dc drawContext) {
+	if !p.visible {
+		return
+	}
+	x, y, w, h := p.screenRect()
+	border := uiBorder
+	if p.focused {
+		border = uiAccent
+	}
+	fillRect(dc, x, y, w, h, color.White)
+	strokeRect(dc, x, y, w, h, uiBorderWidth, border)
+
+	font := getOrCreateFont(p.fontSize())
+	text, clr := p.text, uiText
+	if p.focused && time.Now().UnixMilli()/500%2 == 0 { // blinking caret
+		text += "|"
+	} else if text == "" {
+		text, clr = p.placeholder, uiPlaceholder
+	}
+	drawTextIn(dc, font, text, x, y, w, h, clr, false)
+}
+
+func (p *TextField) hit(hc hitContext) (hr hitResult, ok bool) {
+	if p.hitTest(hc) {
+		return hitResult{Target: p}, true
+	}
+	return
+}
+
+func (p *TextField) doWhenClick(this threadObj) {
+	p.game.setFocus(p)
+}
+
+// textInput is the keyboard input of a tick, read in Update and applied to
+// the focused text field in a coroutine.
+type textInput struct {
+	chars     []rune
+	backspace bool
+	enter     bool
+}
+
+func readTextInput() *textInput {
+	return &textInput{
+		chars:     ebiten.AppendInputChars(nil),
+		backspace: inpututil.IsKeyJustPressed(ebiten.KeyBackspace),
+		enter:     inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyKPEnter),
+	}
+}
+
+func (p *textInput) empty() bool {
+	return len(p.chars) == 0 && !p.backspace && !p.enter
+}
+
+// editText returns text edited by in, with at most maxLength runes if
+// maxLength > 0.
+func editText(text string, in *textInput, maxLength int) string {
+	for _, r := range in.chars {
+		if maxLength > 0 && utf8.RuneCountInString(text) >= maxLength {
+			break
+		}
+		text += string(r)
+	}
+	if in.backspace && text != "" {
+		_, n := utf8.DecodeLastRuneInString(text)
+		text = text[:len(text)-n]
+	}
+	return text
+}
+
+func (p *TextField) updateInput(in *textInput) {
+	if !p.visible {
+		p.game.setFocus(nil)
+		return
+	}
+	old := p.text
+	p.text = editText(p.text, in, p.maxLength)
+	if p.text != old {
+		text := p.text
+		p.onChange.asyncCall(false, text, func(ev *eventSink) {
+			ev.sink.(func(string))(text)
+		})
+	}
+	if in.enter {
+		text := p.text
+		p.onSubmit.asyncCall(false, text, func(ev *eventSink) {
+			ev.sink.(func(string))(text)
+		})
+	}
+}
+
+// OnChange registers a callback which is called when the text is edited.
+func (p *TextField) OnChange(onChange func(text string)) {
+	p.onChange = &eventSink{prev: p.onChange, pthis: p.game, sink: onChange}
+}
+
+// OnSubmit registers a callback which is called when Enter is pressed.
+func (p *TextField) OnSubmit(onSubmit func(text string)) {
+	p.onSubmit = &eventSink{prev: p.onSubmit, pthis: p.game, sink: onSubmit}
+}
+
+func (p *TextField) Text() string {
+	return p.text
+}
+
+func (p *TextField) SetText(text string) {
+	p.text = text
+}
+
+func (p *TextField) Focused() bool {
+	return p.focused
+}
+
+func (p *TextField) Focus() {
+	p.game.setFocus(p)
+}
+
+// -------------------------------------------------------------------------------------
+
+// Checkbox class.
+type Checkbox struct {
+	widgetBase
+	text     string
+	checked  bool
+	color    Color
+	onChange *eventSink
+}
+
+/*
+"type": "checkbox",
+"name": "sound",
+"text": "Sound",
+"checked": true,
+"x": -50,
+"y": -40
+*/
+func newCheckbox(g *Game, v specsp) *Checkbox {
+	p := &Checkbox{}
+	p.initWith(g, v, 100, 18)
+	p.text, _ = getSpcspVal(v, "text", "").(string)
+	p.checked, _ = getSpcspVal(v, "checked", false).(bool)
+	p.color = parseColorOr(v, "color", uiAccent)
+	return p
+}
+
+func (p *Checkbox) draw(dc drawContext) {
+	if !p.visible {
+		return
+	}
+	x, y, w, h := p.screenRect()
+	box := h
+	fillRect(dc, x, y, box, box, color.White)
+	strokeRect(dc, x, y, box, box, uiBorderWidth, uiBorder)
+	if p.checked {
+		inset := math.Max(box/4, 2)
+		fillRect(dc, x+inset, y+inset, box-inset*2, box-inset*2, p.color)
+	}
+	drawTextIn(dc, getOrCreateFont(p.fontSize()), p.text, x+box, y, w-box, h, uiText, false)
+}
+
+func (p *Checkbox) hit(hc hitContext) (hr hitResult, ok bool) {
+	if p.hitTest(hc) {
+		return hitResult{Target: p}, true
+	}
+	return
+}
+
+func (p *Checkbox) doWhenClick(this threadObj) {
+	p.SetChecked(!p.checked)
+}
+
+// OnChange registers a callback which is called when the checked state changes.
+func (p *Checkbox) OnChange(onChange func(checked bool)) {
+	p.onChange = &eventSink{prev: p.onChange, pthis: p.game, sink: onChange}
+}
+
+func (p *Checkbox) Checked() bool {
+	return p.checked
+}
+
+func (p *Checkbox) SetChecked(checked bool) {
+	if p.checked == checked {
+		return
+	}
+	p.checked = checked
+	p.onChange.asyncCall(false, checked, func(ev *eventSink) {
+		ev.sink.(func(bool))(checked)
+	})
+}
+
+// -------------------------------------------------------------------------------------
+
+// ProgressBar class.
+type ProgressBar struct {
+	widgetBase
+	value    float64
+	min, max float64
+	color    Color
+	bgColor  Color
+}
+
+/*
+"type": "progressBar",
+"name": "hp",
+"value": 80,
+"min": 0,
+"max": 100,
+"color": "green",
+"x": -100,
+"y": 150,
+"width": 200,
+"height": 12
+*/
+func newProgressBar(g *Game, v specsp) *ProgressBar {
+	p := &ProgressBar{}
+	p.initWith(g, v, 100, 10)
+	p.min, _ = getSpcspVal(v, "min", 0.0).(float64)
+	p.max, _ = getSpcspVal(v, "max", 100.0).(float64)
+	p.value, _ = getSpcspVal(v, "value", 0.0).(float64)
+	p.color = parseColorOr(v, "color", uiAccent)
+	p.bgColor = parseColorOr(v, "background", uiBackground)
+	if p.max <= p.min {
+		log.Println("[WARN] ProgressBar: max should be greater than min -", p.name)
+		p.max = p.min + 1
+	}
+	return p
+}
+
+func (p *ProgressBar) draw(dc drawContext) {
+	if !p.visible {
+		return
+	}
+	x, y, w, h := p.screenRect()
+	fillRect(dc, x, y, w, h, p.bgColor)
+	fillRect(dc, x, y, w*p.Progress(), h, p.color)
+	strokeRect(dc, x, y, w, h, uiBorderWidth, uiBorder)
+}
+
+func (p *ProgressBar) hit(hc hitContext) (hr hitResult, ok bool) {
+	return
+}
+
+func (p *ProgressBar) Value() float64 {
+	return p.value
+}
+
+func (p *ProgressBar) SetValue(v float64) {
+	p.value = math32.Clamp(v, p.min, p.max)
+}
+
+func (p *ProgressBar) ChangeValue(delta float64) {
+	p.SetValue(p.value + delta)
+}
+
+// Progress returns the value mapped into range [0, 1].
+func (p *ProgressBar) Progress() float64 {
+	return math32.Clamp((p.value-p.min)/(p.max-p.min), 0, 1)
+}
+
+// -------------------------------------------------------------------------------------
+
+func (p *Game) doLayout(winW, winH int) {
+	for _, item := range p.items {
+		if o, ok := item.(layouter); ok {
+			o.layout(winW, winH)
+		}
+	}
+}
+
+func (p *Game) setFocus(tf *TextField) {
+	if p.focused == tf {
+		return
+	}
+	if p.focused != nil {
+		p.focused.focused = false
+	}
+	if tf != nil {
+		tf.focused = true
+	}
+	p.focused = tf
+}
+
+// updateFocus reads the keyboard input of the tick, and edits the focused
+// text field with it in a coroutine, as scripts read and set its text.
+func (p *Game) updateFocus() {
+	in := readTextInput()
+	if in.empty() {
+		return
+	}
+	gco.CreateAndStart(true, nil, func(me coroutine.Thread) int {
+		if tf := p.focused; tf != nil {
+			tf.updateInput(in)
+		}
+		return 0
+	})
+}
+
+// typing checks if a visible text field has the focus, which receives keys
+// instead of the sprites.
+func (p *Game) typing() bool {
+	tf := p.focused
+	return tf != nil && tf.visible
+}
+
+// -------------------------------------------------------------------------------------
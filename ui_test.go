@@ -0,0 +1,82 @@
+package spx
+
+import (
+	"testing"
+)
+
+func TestEditText(t *testing.T) {
+	for _, tc := range []struct {
+		text      string
+		in        textInput
+		maxLength int
+		want      string
+	}{
+		{"ab", textInput{chars: []rune("cd")}, 0, "abcd"},
+		{"ab", textInput{chars: []rune("cde")}, 4, "abcd"},
+		{"ab", textInput{chars: []rune("c"), backspace: true}, 0, "ab"},
+		{"héé", textInput{backspace: true}, 0, "hé"},
+		{"", textInput{backspace: true}, 0, ""},
+	} {
+		if got := editText(tc.text, &tc.in, tc.maxLength); got != tc.want {
+			t.Fatalf("editText(%q, %v, %d) = %q, expected %q", tc.text, tc.in, tc.maxLength, got, tc.want)
+		}
+	}
+	if !(&textInput{}).empty() || (&textInput{enter: true}).empty() {
+		t.Fatal("textInput.empty")
+	}
+}
+
+func TestTextFieldFocus(t *testing.T) {
+	g := new(Game)
+	tf1 := &TextField{widgetBase: widgetBase{game: g, visible: true}}
+	tf2 := &TextField{widgetBase: widgetBase{game: g, visible: true}, maxLength: 3}
+	tf1.Focus()
+	tf2.Focus()
+	if tf1.Focused() || !tf2.Focused() || !g.typing() {
+		t.Fatal("Focus:", tf1.Focused(), tf2.Focused())
+	}
+	tf2.updateInput(&textInput{chars: []rune("abcd")})
+	if tf2.Text() != "abc" {
+		t.Fatal("updateInput:", tf2.Text())
+	}
+	tf2.Hide()
+	if g.typing() {
+		t.Fatal("typing in a hidden text field")
+	}
+	tf2.updateInput(&textInput{chars: []rune("x")})
+	if tf2.Focused() || tf2.Text() != "abc" {
+		t.Fatal("updateInput of a hidden text field:", tf2.Focused(), tf2.Text())
+	}
+}
+
+func TestWidgetAnchor(t *testing.T) {
+	for _, tc := range []struct {
+		anchor string
+		x, y   float64
+	}{
+		{"topLeft", 10, 20},
+		{"top", 390, 20},
+		{"bottomRight", 750, 460},
+		{"center", 390, 220},
+	} {
+		p := &widgetBase{x: 10, y: 20, width: 20, height: 20, size: 2, anchor: toAnchor(tc.anchor), winW: 800, winH: 520}
+		if x, y, w, h := p.screenRect(); x != tc.x || y != tc.y || w != 40 || h != 40 {
+			t.Fatal("screenRect:", tc.anchor, x, y, w, h)
+		}
+	}
+	if toAnchor("middle") != anchorNone {
+		t.Fatal("toAnchor: unknown anchor")
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	p := &ProgressBar{min: 10, max: 30}
+	p.SetValue(15)
+	if p.Progress() != 0.25 {
+		t.Fatal("Progress:", p.Progress())
+	}
+	p.ChangeValue(100)
+	if p.Value() != 30 || p.Progress() != 1 {
+		t.Fatal("ChangeValue:", p.Value(), p.Progress())
+	}
+}
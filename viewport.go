@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"math"
+
+	"github.com/goplus/spx/internal/math32"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// -------------------------------------------------------------------------------------
+
+// Viewport is an additional camera which renders the world into a rectangle
+// of the window, on top of the main camera. It can be used for minimaps,
+// split-screen and picture-in-picture views.
+type Viewport struct {
+	Camera
+	name                string
+	x, y, width, height float64 // in window pixels
+	background          Color
+	border              Color
+	hasBorder           bool
+	visible             bool
+}
+
+func newViewport(g *Game, conf *viewportConfig) *Viewport {
+	worldW, worldH := g.worldSize_()
+	p := &Viewport{
+		name:       conf.Name,
+		x:          conf.X,
+		y:          conf.Y,
+		width:      conf.Width,
+		height:     conf.Height,
+		background: color.RGBA{A: 0xff},
+		visible:    conf.Visible == nil || *conf.Visible,
+	}
+	p.Camera.init(g, conf.Width, conf.Height, float64(worldW), float64(worldH))
+	if conf.Zoom != 0 {
+		p.SetZoom(conf.Zoom)
+	}
+	if c, err := parseColor(conf.Background); err == nil {
+		p.background = c
+	}
+	if c, err := parseColor(conf.Border); err == nil {
+		p.border, p.hasBorder = c, true
+	}
+	p.Camera.initWith(&conf.cameraConfig)
+	return p
+}
+
+func (p *Viewport) Name() string {
+	return p.name
+}
+
+// SetRect sets the position and size of the viewport in window pixels.
+func (p *Viewport) SetRect(x, y, width, height float64) {
+	p.x, p.y, p.width, p.height = x, y, width, height
+	p.freecamera.SetViewPort(width, height)
+}
+
+func (p *Viewport) Show() {
+	p.visible = true
+}
+
+func (p *Viewport) Hide() {
+	p.visible = false
+}
+
+func (p *Viewport) Visible() bool {
+	return p.visible
+}
+
+func (p *Viewport) contains(x, y float64) bool {
+	return x >= p.x && x < p.x+p.width && y >= p.y && y < p.y+p.height
+}
+
+func (p *Viewport) render(world, screen *ebiten.Image) {
+	rc := image.Rect(int(p.x), int(p.y), int(p.x+p.width), int(p.y+p.height))
+	dst, ok := screen.SubImage(rc).(*ebiten.Image)
+	if !ok || rc.Empty() {
+		return
+	}
+	dst.Fill(p.background)
+	p.freecamera.RenderAt(world, dst, p.x, p.y)
+	if p.hasBorder {
+		strokeRect(drawContext{Image: screen}, p.x, p.y, p.width, p.height, uiBorderWidth, p.border)
+	}
+}
+
+// -------------------------------------------------------------------------------------
+
+func (p *Game) initViewports(confs []*viewportConfig) {
+	p.viewports = make([]*Viewport, 0, len(confs))
+	for _, conf := range confs {
+		p.viewports = append(p.viewports, newViewport(p, conf))
+	}
+}
+
+// Viewport returns the viewport with the specified name, or nil if not found.
+func (p *Game) Viewport(name string) *Viewport {
+	for _, vp := range p.viewports {
+		if vp.name == name {
+			return vp
+		}
+	}
+	log.Println("Viewport not found:", name)
+	return nil
+}
+
+// cameraAt returns the camera which renders the window point (x, y), that is
+// the topmost visible viewport under it or the main camera, and the point in
+// world image coordinates.
+func (p *Game) cameraAt(x, y int) (*Camera, *math32.Vector2) {
+	fx, fy := float64(x), float64(y)
+	for i := len(p.viewports) - 1; i >= 0; i-- {
+		vp := p.viewports[i]
+		if vp.visible && vp.contains(fx, fy) {
+			return &vp.Camera, vp.screenToWorld(math32.NewVector2(fx-vp.x, fy-vp.y))
+		}
+	}
+	return &p.Camera, p.Camera.screenToWorld(math32.NewVector2(fx, fy))
+}
+
+// viewRect returns the area of the world image seen by the main camera and all
+// visible viewports, in world image pixels.
+func (p *Game) viewRect() (minX, minY, maxX, maxY float64) {
+	minX, minY, maxX, maxY = p.Camera.viewRect()
+	for _, vp := range p.viewports {
+		if vp.visible {
+			x0, y0, x1, y1 := vp.viewRect()
+			minX, minY = math.Min(minX, x0), math.Min(minY, y0)
+			maxX, maxY = math.Max(maxX, x1), math.Max(maxY, y1)
+		}
+	}
+	return
+}
+
+// -------------------------------------------------------------------------------------
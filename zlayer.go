@@ -0,0 +1,180 @@
+/*
+ * Copyright (c) 2024 The GoPlus Authors (goplus.org). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spx
+
+import (
+	"log"
+	"sort"
+
+	"github.com/goplus/spx/internal/coroutine"
+)
+
+// -------------------------------------------------------------------------------------
+
+// zLayer is a named group of shapes on the stage. Shapes of a layer are always
+// drawn on top of shapes of the layers before it. GotoFront, GotoBack and
+// GoBackLayers move a sprite within its layer.
+//
+//	"layers": [
+//	  {"name": "background"},
+//	  {"name": "world", "ysort": true, "default": true},
+//	  {"name": "fx"},
+//	  {"name": "ui"}
+//	]
+type zLayer struct {
+	name  string
+	ysort bool // sprites with a lower y are drawn on top, for top-down games
+}
+
+func (p *Game) initZLayers(confs []*layerConfig) {
+	p.defaultZLayer = 0
+	p.shapeZLayers = make(map[Shape]int)
+	if len(confs) == 0 {
+		p.zLayers = []zLayer{{}}
+		return
+	}
+	p.zLayers = make([]zLayer, len(confs))
+	for i, conf := range confs {
+		p.zLayers[i] = zLayer{name: conf.Name, ysort: conf.YSort}
+		if conf.Default {
+			p.defaultZLayer = i
+		}
+	}
+}
+
+// zLayerIndex returns the index of the layer with the specified name, or the
+// default layer if not found.
+func (p *Game) zLayerIndex(name string) int {
+	for i, layer := range p.zLayers {
+		if layer.name == name {
+			return i
+		}
+	}
+	if name != "" {
+		log.Println("Layer not found:", name)
+	}
+	return p.defaultZLayer
+}
+
+// zLayerOf returns the index of the layer of a shape. Speech bubbles are on the
+// top layer.
+func (p *Game) zLayerOf(item Shape) int {
+	switch v := item.(type) {
+	case *SpriteImpl:
+		return v.zLayer
+	case *sayOrThinker, *quoter:
+		return len(p.zLayers) - 1
+	}
+	if idx, ok := p.shapeZLayers[item]; ok {
+		return idx
+	}
+	return p.defaultZLayer
+}
+
+// zLayerRange returns the range [from, to) of items in the layer idx.
+func (p *Game) zLayerRange(items []Shape, idx int) (from, to int) {
+	from = sort.Search(len(items), func(i int) bool {
+		return p.zLayerOf(items[i]) >= idx
+	})
+	to = sort.Search(len(items), func(i int) bool {
+		return p.zLayerOf(items[i]) > idx
+	})
+	return
+}
+
+// SetYSort turns y-sorting of the specified layer on or off.
+func (p *Game) SetYSort(layer string, on bool) {
+	for i := range p.zLayers {
+		if p.zLayers[i].name == layer {
+			p.zLayers[i].ysort = on
+			return
+		}
+	}
+	log.Println("SetYSort: layer not found -", layer)
+}
+
+// ySortZLayers y-sorts layers once a tick. Like the tick handlers, it runs in
+// a coroutine, as scripts add and remove items meanwhile.
+func (p *Game) ySortZLayers() {
+	gco.CreateAndStart(true, nil, func(me coroutine.Thread) int {
+		p.doYSortZLayers()
+		return 0
+	})
+}
+
+// doYSortZLayers sorts the sprites of y-sorted layers by their y, so that the
+// lower ones are drawn on top. Other shapes keep their places.
+func (p *Game) doYSortZLayers() {
+	var newItems []Shape
+	for idx, layer := range p.zLayers {
+		if !layer.ysort {
+			continue
+		}
+		items := p.items
+		if newItems != nil {
+			items = newItems
+		}
+		from, to := p.zLayerRange(items, idx)
+		var slots []int
+		var sprites []*SpriteImpl
+		for i := from; i < to; i++ {
+			if sp, ok := items[i].(*SpriteImpl); ok {
+				slots = append(slots, i)
+				sprites = append(sprites, sp)
+			}
+		}
+		if sort.SliceIsSorted(sprites, func(i, j int) bool { return sprites[i].y > sprites[j].y }) {
+			continue
+		}
+		sort.SliceStable(sprites, func(i, j int) bool { return sprites[i].y > sprites[j].y })
+		if newItems == nil {
+			// getItems() requires immutable items, so we need copy before modify
+			newItems = make([]Shape, len(items))
+			copy(newItems, items)
+		}
+		for i, slot := range slots {
+			newItems[slot] = sprites[i]
+		}
+	}
+	if newItems != nil {
+		p.items = newItems
+	}
+}
+
+// -------------------------------------------------------------------------------------
+
+// SetLayer moves the sprite to the front of the specified layer.
+func (p *SpriteImpl) SetLayer(layer string) {
+	idx := p.g.zLayerIndex(layer)
+	if idx == p.zLayer {
+		return
+	}
+	if p.g.doFindSprite(p) < 0 {
+		p.zLayer = idx
+		return
+	}
+	p.g.removeShape(p)
+	p.zLayer = idx
+	p.g.addShape(p)
+}
+
+// Layer returns the name of the layer of the sprite.
+func (p *SpriteImpl) Layer() string {
+	return p.g.zLayers[p.zLayer].name
+}
+
+// -------------------------------------------------------------------------------------
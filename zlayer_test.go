@@ -0,0 +1,61 @@
+package spx
+
+import (
+	"testing"
+)
+
+func newZLayerTestGame(layers []int) (*Game, []*SpriteImpl) {
+	g := &Game{zLayers: make([]zLayer, 3)}
+	sprites := make([]*SpriteImpl, len(layers))
+	for i, layer := range layers {
+		sprites[i] = &SpriteImpl{zLayer: layer}
+		sprites[i].g = g
+		g.items = append(g.items, sprites[i])
+	}
+	return g, sprites
+}
+
+func checkItems(t *testing.T, g *Game, sprites []*SpriteImpl, want ...int) {
+	t.Helper()
+	for i, idx := range want {
+		if g.items[i] != sprites[idx] {
+			t.Fatal("item", i, "is not sprite", idx)
+		}
+	}
+}
+
+func TestZLayerRange(t *testing.T) {
+	g, _ := newZLayerTestGame([]int{0, 1, 1, 1, 2})
+	for idx, want := range [][2]int{{0, 1}, {1, 4}, {4, 5}} {
+		if from, to := g.zLayerRange(g.items, idx); from != want[0] || to != want[1] {
+			t.Fatal("zLayerRange:", idx, from, to)
+		}
+	}
+	g, _ = newZLayerTestGame([]int{0, 2})
+	if from, to := g.zLayerRange(g.items, 1); from != 1 || to != 1 {
+		t.Fatal("zLayerRange of an empty layer:", from, to)
+	}
+}
+
+func TestGoBackLayersWithinLayer(t *testing.T) {
+	g, sprites := newZLayerTestGame([]int{0, 1, 1, 1, 2})
+	sprites[1].GotoFront()
+	checkItems(t, g, sprites, 0, 2, 3, 1, 4)
+	sprites[1].GoBackLayers(1)
+	checkItems(t, g, sprites, 0, 2, 1, 3, 4)
+	sprites[1].GotoBack()
+	checkItems(t, g, sprites, 0, 1, 2, 3, 4)
+	sprites[4].GotoBack()
+	sprites[0].GotoFront()
+	checkItems(t, g, sprites, 0, 1, 2, 3, 4)
+}
+
+func TestYSortZLayers(t *testing.T) {
+	g, sprites := newZLayerTestGame([]int{0, 1, 1, 1, 2})
+	g.zLayers[1].ysort = true
+	for i, y := range []float64{0, -10, 20, 5, 100} {
+		sprites[i].y = y
+	}
+	g.doYSortZLayers()
+	checkItems(t, g, sprites, 0, 2, 3, 1, 4)
+}